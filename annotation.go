@@ -0,0 +1,135 @@
+package reflectshape
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Annotation is the marker comment attached to a declaration.
+//
+//	//+enum                 -> {Prefix: "+", Name: "enum", Value: ""}
+//	// @route GET /users    -> {Prefix: "@", Name: "route", Value: "GET /users"}
+//	//api:internal          -> {Prefix: "", Name: "api:internal", Value: ""}
+//
+// The comments in directive form ("//<name>:<arg>", without spaces) are always treated as annotations.
+type Annotation struct {
+	Prefix string
+	Name   string
+	Value  string
+	Pos    token.Pos
+}
+
+func (a *Annotation) String() string {
+	if a.Value == "" {
+		return a.Prefix + a.Name
+	}
+	return a.Prefix + a.Name + " " + a.Value
+}
+
+type AnnotationList []*Annotation
+
+// Lookup returns the first annotation with the name.
+func (al AnnotationList) Lookup(name string) (*Annotation, bool) {
+	for _, a := range al {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Filter returns the annotations with the name.
+func (al AnnotationList) Filter(name string) AnnotationList {
+	var r AnnotationList
+	for _, a := range al {
+		if a.Name == name {
+			r = append(r, a)
+		}
+	}
+	return r
+}
+
+// annotations returns the annotations of the declaration at pos.
+func (e *Extractor) annotations(pos token.Pos) AnnotationList {
+	if e.Lookup == nil || !pos.IsValid() {
+		return nil
+	}
+
+	var r AnnotationList
+	doc, comment := e.Lookup.CommentGroups(pos)
+	for _, cg := range []*ast.CommentGroup{doc, comment} {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, "//") {
+				continue // /* */ style is not supported
+			}
+			if a := e.parseAnnotation(c.Text[2:]); a != nil {
+				a.Pos = c.Pos()
+				r = append(r, a)
+			}
+		}
+	}
+	return r
+}
+
+// parseAnnotation parses the comment line (without "//"), if it is not an annotation, returns nil.
+func (e *Extractor) parseAnnotation(line string) *Annotation {
+	if isDirective(line) {
+		name, value, _ := strings.Cut(line, " ")
+		return &Annotation{Name: name, Value: strings.TrimSpace(value)}
+	}
+	return e.parsePrefixedAnnotation(line)
+}
+
+func (e *Extractor) parsePrefixedAnnotation(line string) *Annotation {
+	line = strings.TrimSpace(line)
+	for _, prefix := range e.Config.AnnotationPrefixes {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		name, value, _ := strings.Cut(line[len(prefix):], " ")
+		if name == "" {
+			continue
+		}
+		return &Annotation{Prefix: prefix, Name: name, Value: strings.TrimSpace(value)}
+	}
+	return nil
+}
+
+// stripAnnotations removes the annotation lines from the doc text.
+func (e *Extractor) stripAnnotations(doc string) string {
+	if doc == "" || len(e.Config.AnnotationPrefixes) == 0 {
+		return doc
+	}
+
+	lines := strings.Split(doc, "\n")
+	r := lines[:0]
+	for _, line := range lines {
+		if e.parsePrefixedAnnotation(line) != nil { // directives are already dropped by go/ast
+			continue
+		}
+		r = append(r, line)
+	}
+	return strings.TrimSpace(strings.Join(r, "\n"))
+}
+
+// isDirective reports whether c is a comment directive (see go/ast's isDirective).
+func isDirective(c string) bool {
+	colon := strings.Index(c, ":")
+	if colon <= 0 || colon+1 >= len(c) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := c[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
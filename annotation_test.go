@@ -0,0 +1,127 @@
+package reflectshape_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// Color is the color of something.
+//
+// +enum
+type Color string
+
+// Endpoint is the annotated object.
+// @resource users
+//
+//api:internal
+type Endpoint struct {
+	// ID of endpoint
+	// @readonly
+	ID string
+
+	Name string   // name of endpoint +required
+	Tags []string // @example a,b
+}
+
+// ListUsers returns users.
+// @route GET /users
+func ListUsers(
+	limit int, // @in query
+	cursor string,
+) ([]string, error) {
+	return nil, nil
+}
+
+func TestAnnotations(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true, AnnotationPrefixes: []string{"+", "@"}}
+
+	names := func(al reflectshape.AnnotationList) []string {
+		var r []string
+		for _, a := range al {
+			r = append(r, a.String())
+		}
+		return r
+	}
+
+	t.Run("named", func(t *testing.T) {
+		named := cfg.Extract(Color("")).Named()
+		if diff := cmp.Diff([]string{"+enum"}, names(named.Annotations())); diff != "" {
+			t.Errorf("Named.Annotations(): -want, +got: \n%v", diff)
+		}
+		if diff := cmp.Diff("Color is the color of something.", named.Doc()); diff != "" {
+			t.Errorf("Named.Doc(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		s := cfg.Extract(Endpoint{}).Struct()
+		if diff := cmp.Diff([]string{"@resource users", "api:internal"}, names(s.Annotations())); diff != "" {
+			t.Errorf("Struct.Annotations(): -want, +got: \n%v", diff)
+		}
+		if diff := cmp.Diff("Endpoint is the annotated object.", s.Doc()); diff != "" {
+			t.Errorf("Struct.Doc(): -want, +got: \n%v", diff)
+		}
+
+		fields := s.Fields()
+		var got [][]string
+		var docs []string
+		for _, f := range fields {
+			got = append(got, names(f.Annotations))
+			docs = append(docs, f.Doc)
+		}
+		want := [][]string{{"@readonly"}, nil, {"@example a,b"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Field.Annotations: -want, +got: \n%v", diff)
+		}
+		// only the line starts with the prefix is treated as annotation
+		if diff := cmp.Diff([]string{"ID of endpoint", "name of endpoint +required", ""}, docs); diff != "" {
+			t.Errorf("Field.Doc: -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("func", func(t *testing.T) {
+		fn := cfg.Extract(ListUsers).Func()
+		a, ok := fn.Annotations().Lookup("route")
+		if !ok {
+			t.Fatalf("Func.Annotations().Lookup(): route is not found")
+		}
+		if diff := cmp.Diff("GET /users", a.Value); diff != "" {
+			t.Errorf("Annotation.Value: -want, +got: \n%v", diff)
+		}
+
+		var got [][]string
+		for _, v := range fn.Args() {
+			got = append(got, names(v.Annotations))
+		}
+		if diff := cmp.Diff([][]string{{"@in query"}, nil}, got); diff != "" {
+			t.Errorf("Var.Annotations: -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("custom-prefix", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true, AnnotationPrefixes: []string{"@"}}
+		named := cfg.Extract(Color("")).Named()
+		if got := named.Annotations(); len(got) != 0 {
+			t.Errorf("Named.Annotations(): must be empty, but %v", names(got))
+		}
+		if diff := cmp.Diff("Color is the color of something.\n\n+enum", named.Doc()); diff != "" {
+			t.Errorf("Named.Doc(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true} // no prefixes, the docs are not changed
+		s := cfg.Extract(Endpoint{}).Struct()
+		if diff := cmp.Diff([]string{"api:internal"}, names(s.Annotations())); diff != "" {
+			t.Errorf("Struct.Annotations(): -want, +got: \n%v", diff)
+		}
+		if diff := cmp.Diff("Endpoint is the annotated object.\n@resource users", s.Doc()); diff != "" {
+			t.Errorf("Struct.Doc(): -want, +got: \n%v", diff)
+		}
+		if diff := cmp.Diff("ID of endpoint\n@readonly", s.Fields()[0].Doc); diff != "" {
+			t.Errorf("Field.Doc: -want, +got: \n%v", diff)
+		}
+	})
+}
@@ -13,6 +13,9 @@ type Config struct {
 	IncludeGoTestFiles bool
 
 	DocTruncationSize int
+	DocLinkBaseURL    string // base URL of doc links to the other packages, default is "https://pkg.go.dev"
+
+	AnnotationPrefixes []string // marker prefixes of annotation comments (e.g. ["+", "@"]), default is empty (only the directives are annotations)
 
 	Fset      *token.FileSet
 	extractor *Extractor
@@ -21,12 +24,21 @@ type Config struct {
 
 var (
 	DocTruncationSize = 10
+	DocLinkBaseURL    = "https://pkg.go.dev"
+
+	AnnotationPrefixes []string // opt-in, because the annotation lines are stripped from the docs
 )
 
 func (c *Config) Extract(ob interface{}) *Shape {
 	if c.DocTruncationSize == 0 {
 		c.DocTruncationSize = DocTruncationSize
 	}
+	if c.DocLinkBaseURL == "" {
+		c.DocLinkBaseURL = DocLinkBaseURL
+	}
+	if c.AnnotationPrefixes == nil {
+		c.AnnotationPrefixes = AnnotationPrefixes
+	}
 
 	if c.lookup == nil && !c.SkipComments {
 		if c.Fset == nil {
@@ -47,6 +59,22 @@ func (c *Config) Extract(ob interface{}) *Shape {
 	return c.extractor.Extract(ob)
 }
 
+// Visited returns the copy of the visited shapes.
 func (c *Config) Visited() map[ID]*Shape {
 	return c.extractor.Visited()
 }
+
+// VisitedShapes returns the visited shapes, in the order of extraction (Shape.Number).
+func (c *Config) VisitedShapes() []*Shape {
+	return c.extractor.VisitedShapes()
+}
+
+// Reachable returns the shapes reachable from the roots (including the roots), in the order of extraction (Shape.Number).
+func (c *Config) Reachable(roots ...*Shape) []*Shape {
+	return c.extractor.Reachable(roots...)
+}
+
+// Snapshot returns the snapshot of the exported shapes reachable from the visited shapes.
+func (c *Config) Snapshot() *Snapshot {
+	return c.extractor.Snapshot()
+}
@@ -3,7 +3,11 @@ package reflectshape_test
 import (
 	"context"
 	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -102,6 +106,9 @@ func TestPackagePath(t *testing.T) {
 		{msg: "struct", input: S0{}, pkgpath: "github.com/podhmo/reflect-shape_test"},
 		{msg: "struct-pointer", input: &S0{}, pkgpath: "github.com/podhmo/reflect-shape_test"},
 		{msg: "func", input: F0, pkgpath: "github.com/podhmo/reflect-shape_test"},
+		{msg: "closure", input: func() {}, pkgpath: "github.com/podhmo/reflect-shape_test"},
+		{msg: "method-value", input: new(S1).M, pkgpath: "github.com/podhmo/reflect-shape_test"},
+		{msg: "method-expression", input: (*S1).M, pkgpath: "github.com/podhmo/reflect-shape_test"},
 		{msg: "slice", input: []S0{}, pkgpath: ""},
 		// stdlib
 		{msg: "int", input: int(0), pkgpath: ""},
@@ -118,6 +125,81 @@ func TestPackagePath(t *testing.T) {
 	}
 }
 
+func TestPackageName(t *testing.T) {
+	cases := []struct {
+		msg      string
+		input    any
+		name     string // guessed from the package path
+		declared string
+	}{
+		{msg: "struct", input: S0{}, name: "reflect", declared: "reflectshape_test"},
+		{msg: "func", input: F0, name: "reflect", declared: "reflectshape_test"},
+		{msg: "int", input: int(0), name: "", declared: ""},
+		{msg: "stdlib-func", input: t.Run, name: "testing", declared: "testing"},
+		{msg: "dir-name-differs", input: reflectshape.Config{}, name: "reflect", declared: "reflectshape"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			shape := cfg.Extract(c.input)
+			if want, got := c.name, shape.Package.Name; want != got {
+				t.Errorf("Shape.Package.Name: %#+v != %#+v", want, got)
+			}
+			if want, got := c.declared, shape.Package.DeclaredName(); want != got {
+				t.Errorf("Shape.Package.DeclaredName(): %#+v != %#+v", want, got)
+			}
+		})
+	}
+
+	t.Run("without-source", func(t *testing.T) {
+		shape := (&reflectshape.Config{SkipComments: true}).Extract(reflectshape.Config{})
+		if want, got := shape.Package.Name, shape.Package.DeclaredName(); want != got {
+			t.Errorf("Shape.Package.DeclaredName(): %#+v != %#+v", want, got)
+		}
+	})
+}
+
+func TestPackageModule(t *testing.T) {
+	type module struct {
+		Path    string
+		Version string
+		Main    bool
+	}
+
+	cases := []struct {
+		msg    string
+		input  any
+		module *module
+	}{
+		{msg: "main-module", input: S0{}, module: &module{Path: "github.com/podhmo/reflect-shape", Main: true}},
+		{msg: "dependency", input: cmp.Diff, module: &module{Path: "github.com/google/go-cmp", Version: "v0.5.9"}},
+		{msg: "stdlib", input: t.Run, module: nil},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			for _, cfg := range []*reflectshape.Config{cfg, {SkipComments: true}} {
+				pkg := cfg.Extract(c.input).Package
+
+				var got *module
+				if m := pkg.Module(); m != nil {
+					got = &module{Path: m.Path, Version: m.Version, Main: m.Main}
+				}
+				if diff := cmp.Diff(c.module, got); diff != "" {
+					t.Errorf("Shape.Package.Module() (SkipComments=%v): -want, +got: \n%v", cfg.SkipComments, diff)
+				}
+			}
+		})
+	}
+
+	t.Run("dir", func(t *testing.T) {
+		pkg := cfg.Extract(S0{}).Package
+		if _, err := os.Stat(filepath.Join(pkg.Dir(), "api_test.go")); err != nil {
+			t.Errorf("Shape.Package.Dir(): %q, %+v", pkg.Dir(), err)
+		}
+	})
+}
+
 func TestPackageScopeNames(t *testing.T) {
 	t.Run("one", func(t *testing.T) {
 		want := []string{"F0"}
@@ -151,6 +233,262 @@ func TestPackageScopeNames(t *testing.T) {
 	})
 }
 
+func f2() {}
+
+func TestScopeLookup(t *testing.T) {
+	cfg := &reflectshape.Config{}
+
+	cfg.Extract(S0{})
+	cfg.Extract(&S1{})
+	cfg.Extract(F1)
+	cfg.Extract(f2)
+	cfg.Extract(new(S0).M)
+	anonymous := func() {}
+	shape := cfg.Extract(anonymous)
+	scope := shape.Package.Scope()
+
+	t.Run("lookup", func(t *testing.T) {
+		for _, name := range []string{"S0", "S1", "F1", "f2", "S0.M", "TestScopeLookup.func1"} {
+			got := scope.Lookup(name)
+			if got == nil {
+				t.Errorf("Scope.Lookup(%q): not found", name)
+				continue
+			}
+			if want, got := name, got.Name; want != got {
+				t.Errorf("Scope.Lookup(%q).Name: %q != %q", name, want, got)
+			}
+		}
+		if got := scope.Lookup("S1.M"); got != nil { // not extracted
+			t.Errorf("Scope.Lookup(%q): must be nil, but %v", "S1.M", got)
+		}
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		if want, got := "TestScopeLookup.func1", shape.Name; want != got {
+			t.Errorf("Shape.Name: %q != %q", want, got)
+		}
+		if !shape.IsAnonymous() || shape.IsExported() || shape.IsMethod {
+			t.Errorf("anonymous function: IsAnonymous()=%v, IsExported()=%v, IsMethod=%v", shape.IsAnonymous(), shape.IsExported(), shape.IsMethod)
+		}
+	})
+
+	names := func(shapes []*reflectshape.Shape) []string {
+		r := make([]string, len(shapes))
+		for i, s := range shapes {
+			r[i] = s.Name
+		}
+		return r
+	}
+
+	cases := []struct {
+		msg     string
+		filters []reflectshape.ShapeFilter
+		want    []string
+	}{
+		{msg: "all", want: []string{"F1", "S0", "S0.M", "S1", "TestScopeLookup.func1", "f2"}},
+		{msg: "kind=struct", filters: []reflectshape.ShapeFilter{reflectshape.FilterKind(reflect.Struct)}, want: []string{"S0", "S1"}},
+		{msg: "kind=func", filters: []reflectshape.ShapeFilter{reflectshape.FilterKind(reflect.Func)}, want: []string{"F1", "S0.M", "TestScopeLookup.func1", "f2"}},
+		{msg: "exported", filters: []reflectshape.ShapeFilter{reflectshape.FilterExported(true)}, want: []string{"F1", "S0", "S0.M", "S1"}},
+		{msg: "unexported", filters: []reflectshape.ShapeFilter{reflectshape.FilterExported(false)}, want: []string{"TestScopeLookup.func1", "f2"}},
+		{msg: "method", filters: []reflectshape.ShapeFilter{reflectshape.FilterMethod(true)}, want: []string{"S0.M"}},
+		{msg: "anonymous", filters: []reflectshape.ShapeFilter{reflectshape.FilterAnonymous(true)}, want: []string{"TestScopeLookup.func1"}},
+		{msg: "func-and-exported-and-not-method", filters: []reflectshape.ShapeFilter{reflectshape.FilterKind(reflect.Func), reflectshape.FilterExported(true), reflectshape.FilterMethod(false)}, want: []string{"F1"}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			got := names(scope.Shapes(c.filters...))
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Scope.Shapes(): -want, +got: \n%v", diff)
+			}
+		})
+	}
+}
+
+func TestScopeLookupMethodExpressionAndValue(t *testing.T) {
+	cfg := &reflectshape.Config{SkipComments: true}
+	expr := cfg.Extract(S0.M)
+	value := cfg.Extract(new(S0).M)
+	scope := expr.Package.Scope()
+
+	if want, got := expr.Name, value.Name; want != got {
+		t.Errorf("Shape.Name: %q != %q", want, got)
+	}
+	if want, got := expr, scope.Lookup("S0.M"); want != got { // prefer the method expression
+		t.Errorf("Scope.Lookup(%q): want:%v != got:%v", "S0.M", want, got)
+	}
+	if want, got := []*reflectshape.Shape{expr, value}, scope.Shapes(reflectshape.FilterMethod(true)); !reflect.DeepEqual(want, got) {
+		t.Errorf("Scope.Shapes(): want:%v != got:%v", want, got)
+	}
+	if want, got := []string{"S0.M"}, scope.NamesWithMethod(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Scope.NamesWithMethod(): %#+v != %#+v", want, got)
+	}
+}
+
+func TestShapeIsExported(t *testing.T) {
+	cfg := &reflectshape.Config{SkipComments: true}
+	cases := []struct {
+		msg   string
+		input any
+		want  bool
+	}{
+		{msg: "struct", input: S0{}, want: true},
+		{msg: "func", input: F1, want: true},
+		{msg: "unexported-func", input: f2, want: false},
+		{msg: "method", input: S0.M, want: true},
+		{msg: "generic", input: List[int]{}, want: true},
+		{msg: "generic-with-package-path", input: List[S0]{}, want: true}, // List[github.com/podhmo/reflect-shape_test.S0]
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			shape := cfg.Extract(c.input)
+			if want, got := c.want, shape.IsExported(); want != got {
+				t.Errorf("Shape.IsExported(): %v != %v (Name=%q)", want, got, shape.Name)
+			}
+		})
+	}
+}
+
+type Tree struct {
+	Name     string
+	Children []*Tree
+	Labels   map[string]Label
+}
+
+type Label string
+
+func TestVisited(t *testing.T) {
+	cfg := &reflectshape.Config{SkipComments: true}
+	cfg.Extract(S0{})
+	cfg.Extract(F1)
+	root := cfg.Extract(&Tree{})
+
+	typenames := func(shapes []*reflectshape.Shape) []string {
+		r := make([]string, len(shapes))
+		for i, s := range shapes {
+			r[i] = s.Type.String()
+		}
+		return r
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		want := []string{"reflectshape_test.S0", "func()", "reflectshape_test.Tree"}
+		got := typenames(cfg.VisitedShapes())
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Config.VisitedShapes(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		visited := cfg.Visited()
+		for k := range visited {
+			delete(visited, k)
+		}
+		if want, got := 3, len(cfg.Visited()); want != got {
+			t.Errorf("len(Config.Visited()): %d != %d", want, got)
+		}
+	})
+
+	t.Run("reachable", func(t *testing.T) {
+		want := []string{
+			"reflectshape_test.Tree",
+			"string",
+			"[]*reflectshape_test.Tree",
+			"map[string]reflectshape_test.Label",
+			"reflectshape_test.Label",
+		}
+		got := typenames(cfg.Reachable(root))
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Config.Reachable(): -want, +got: \n%v", diff)
+		}
+		if want, got := 0, cfg.Reachable(root)[0].Lv; want != got { // the root is passed as *Tree
+			t.Errorf("Config.Reachable()[0].Lv: %d != %d", want, got)
+		}
+	})
+
+	t.Run("no-side-effect", func(t *testing.T) {
+		cfg := &reflectshape.Config{SkipComments: true}
+		root := cfg.Extract(&Tree{})
+		want := cfg.VisitedShapes()
+		wantNames := root.Package.Scope().Names()
+
+		cfg.Reachable(root)
+		cfg.Snapshot()
+		root.Snapshot()
+		root.FingerprintWith(reflectshape.FingerprintOptions{IncludeDocs: true})
+
+		if got := cfg.VisitedShapes(); !reflect.DeepEqual(want, got) {
+			t.Errorf("Config.VisitedShapes(): must not be changed, %v != %v", want, got)
+		}
+		if got := root.Package.Scope().Names(); !reflect.DeepEqual(wantNames, got) {
+			t.Errorf("Scope.Names(): must not be changed, %#+v != %#+v", wantNames, got)
+		}
+	})
+
+	t.Run("extract-after-reachable", func(t *testing.T) {
+		cfg := &reflectshape.Config{SkipComments: true}
+		root := cfg.Extract(&Tree{})
+		var label *reflectshape.Shape
+		for _, s := range cfg.Reachable(root) {
+			if s.Name == "Label" {
+				label = s
+			}
+		}
+		if got := cfg.Extract(Label("")); label != got { // registered, with the same number
+			t.Errorf("Config.Extract(): must be the shape found by Reachable(), %v != %v", label, got)
+		}
+		if got := cfg.VisitedShapes(); got[len(got)-1] != label {
+			t.Errorf("Config.VisitedShapes(): the last one must be Label, but %v", got)
+		}
+	})
+}
+
+func TestStableID(t *testing.T) {
+	pkgpath := "github.com/podhmo/reflect-shape_test"
+	cases := []struct {
+		msg   string
+		input any
+		want  string
+	}{
+		{msg: "named", input: Label(""), want: pkgpath + ".Label#473287f8298dba71"}, // sha256("string")
+		{msg: "struct", input: Tree{}, want: pkgpath + ".Tree#a7001bf74dad3eff"},
+		{msg: "pointer", input: &Tree{}, want: pkgpath + ".Tree#a7001bf74dad3eff"},
+		{msg: "unnamed", input: []*Tree{}, want: "[]*" + pkgpath + ".Tree#"},
+		{msg: "func", input: F1, want: pkgpath + ".F1#"},
+		{msg: "method", input: new(S0).M, want: pkgpath + ".S0.M#"},
+		{msg: "builtin", input: "", want: "string#473287f8298dba71"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			// same value, even if the extractor is different
+			got := (&reflectshape.Config{SkipComments: true}).Extract(c.input).StableID()
+			another := cfg.Extract(c.input).StableID()
+			if want, got := c.want, got; !strings.HasPrefix(got, want) {
+				t.Errorf("Shape.StableID(): want prefix %q, but got %q", want, got)
+			}
+			if got != another {
+				t.Errorf("Shape.StableID(): must be same, but %q != %q", got, another)
+			}
+		})
+	}
+
+	t.Run("same-signature-func", func(t *testing.T) {
+		x := (&reflectshape.Config{SkipComments: true}).Extract(F0).StableID()
+		y := (&reflectshape.Config{SkipComments: true}).Extract(F1).StableID()
+		if x == y {
+			t.Errorf("Shape.StableID(): must be different, but %q == %q", x, y)
+		}
+		_, xhash, _ := strings.Cut(x, "#")
+		_, yhash, _ := strings.Cut(y, "#")
+		if xhash != yhash {
+			t.Errorf("Shape.StableID(): the hash must be same, but %q != %q", xhash, yhash)
+		}
+	})
+}
+
 // This is Foo.
 func Foo(ctx context.Context, name string, nickname *string) error {
 	return nil
@@ -387,3 +725,421 @@ func TestNamed(t *testing.T) {
 		})
 	}
 }
+
+func TestPosition(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		s := cfg.Extract(&Person{}).Struct()
+		pos := s.Position()
+		if want, got := "api_test.go", filepath.Base(pos.Filename); want != got {
+			t.Errorf("Shape.Struct().Position().Filename: want:%v != got:%v", want, got)
+		}
+		for i, f := range s.Fields() {
+			if want, got := pos.Line+1+i, f.Position().Line; want != got {
+				t.Errorf("Shape.Struct().Fields()[%d].Position().Line: want:%v != got:%v", i, want, got)
+			}
+		}
+	})
+
+	t.Run("func", func(t *testing.T) {
+		fn := cfg.Extract(Foo).Func()
+		pos := fn.Position()
+		if want, got := "api_test.go", filepath.Base(pos.Filename); want != got {
+			t.Errorf("Shape.Func().Position().Filename: want:%v != got:%v", want, got)
+		}
+		for i, a := range fn.Args() {
+			if want, got := pos.Line, a.Position().Line; want != got {
+				t.Errorf("Shape.Func().Args()[%d].Position().Line: want:%v != got:%v", i, want, got)
+			}
+		}
+	})
+
+	t.Run("func-without-metadata", func(t *testing.T) {
+		cfg := &reflectshape.Config{SkipComments: true}
+		pos := cfg.Extract(Foo).Position()
+		if want, got := "api_test.go", filepath.Base(pos.Filename); want != got {
+			t.Errorf("Shape.Position().Filename: want:%v != got:%v", want, got)
+		}
+		if pos.Line == 0 {
+			t.Errorf("Shape.Position().Line: must not be zero")
+		}
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		if want, got := (token.Position{}), cfg.Extract(0).Position(); want != got {
+			t.Errorf("Shape.Position(): want:%v != got:%v", want, got)
+		}
+	})
+}
+
+// Measurement is the result of measuring.
+type Measurement struct {
+	// Distance from the origin
+	Distance float64 // meters
+	Elapsed  int     // seconds
+}
+
+// Unit of measurement
+type Unit string // e.g. "m", "s"
+
+func TestLeadingDocAndLineComment(t *testing.T) {
+	type result struct {
+		Doc         string
+		LeadingDoc  string
+		LineComment string
+	}
+
+	t.Run("field", func(t *testing.T) {
+		var got []result
+		for _, f := range cfg.Extract(Measurement{}).Struct().Fields() {
+			got = append(got, result{Doc: f.Doc, LeadingDoc: f.LeadingDoc, LineComment: f.LineComment})
+		}
+		want := []result{
+			{Doc: "Distance from the origin", LeadingDoc: "Distance from the origin", LineComment: "meters"},
+			{Doc: "seconds", LeadingDoc: "", LineComment: "seconds"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Shape.Struct().Fields(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("named", func(t *testing.T) {
+		named := cfg.Extract(Unit("m")).Named()
+		got := result{Doc: named.Doc(), LeadingDoc: named.LeadingDoc(), LineComment: named.LineComment()}
+		want := result{Doc: "Unit of measurement", LeadingDoc: "Unit of measurement", LineComment: `e.g. "m", "s"`}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Shape.Named(): -want, +got: \n%v", diff)
+		}
+	})
+}
+
+// Repository is the interface for Person.
+type Repository interface {
+	// Get returns the person.
+	Get(ctx context.Context, id int /* the id of person */) (*Person, error)
+	// Find finds persons.
+	Find(ctx context.Context, name string, limit int) (result []*Person, err error)
+	Count(context.Context) int // count of persons
+}
+
+func UseRepository(repo Repository) {}
+
+func TestInterfaceMethodFuncs(t *testing.T) {
+	type result struct {
+		Name    string
+		Doc     string
+		Recv    string
+		Args    []string
+		ArgDocs []string
+		Returns []string
+	}
+
+	iface := cfg.Extract(UseRepository).Func().Args()[0].Shape.Interface()
+
+	var got []result
+	for _, fn := range iface.MethodFuncs() {
+		r := result{Name: fn.Name(), Doc: fn.Doc(), Recv: fn.Recv()}
+		for _, v := range fn.Args() {
+			r.Args = append(r.Args, v.Name)
+			r.ArgDocs = append(r.ArgDocs, v.Doc)
+		}
+		for _, v := range fn.Returns() {
+			r.Returns = append(r.Returns, v.Name)
+		}
+		if !fn.IsMethod() {
+			t.Errorf("Interface.MethodFuncs()[%s].IsMethod(): must be true", fn.Name())
+		}
+		if want, got := fn.Position(), fn.Shape.Position(); want != got || !got.IsValid() {
+			t.Errorf("Interface.MethodFuncs()[%s].Shape.Position(): want:%v != got:%v", fn.Name(), want, got)
+		}
+		got = append(got, r)
+	}
+
+	want := []result{
+		{Name: "Repository.Count", Doc: "count of persons", Recv: "Repository", Args: []string{""}, ArgDocs: []string{""}, Returns: []string{""}},
+		{Name: "Repository.Find", Doc: "Find finds persons.", Recv: "Repository", Args: []string{"ctx", "name", "limit"}, ArgDocs: []string{"", "", ""}, Returns: []string{"result", "err"}},
+		{Name: "Repository.Get", Doc: "Get returns the person.", Recv: "Repository", Args: []string{"ctx", "id"}, ArgDocs: []string{"", "the id of person"}, Returns: []string{"", ""}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Interface.MethodFuncs(): -want, +got: \n%v", diff)
+	}
+}
+
+// Reader is the interface that wraps the Read method.
+type Reader interface {
+	// Read reads up to len(p) bytes into p.
+	Read(p []byte) (n int, err error)
+}
+
+// Writer is the interface that wraps the Write method.
+type Writer interface {
+	// Write writes len(p) bytes from p.
+	Write(p []byte) (n int, err error)
+}
+
+// ReadWriter is the composition of Reader and Writer.
+type ReadWriter interface {
+	Reader
+	Writer
+	fmt.Stringer
+
+	// Close closes.
+	Close() error
+}
+
+func UseReadWriter(rw ReadWriter) {}
+
+func TestInterfaceEmbedded(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	cfg.Extract((*Reader)(nil))
+
+	iface := cfg.Extract(UseReadWriter).Func().Args()[0].Shape.Interface()
+
+	t.Run("embedded", func(t *testing.T) {
+		type ref struct {
+			Name     string
+			PkgPath  string
+			HasShape bool
+		}
+		var got []ref
+		for _, x := range iface.Embedded() {
+			got = append(got, ref{Name: x.Name, PkgPath: x.PkgPath, HasShape: x.Shape != nil})
+		}
+		want := []ref{
+			{Name: "Reader", PkgPath: "github.com/podhmo/reflect-shape_test", HasShape: true}, // extracted
+			{Name: "Writer", PkgPath: "github.com/podhmo/reflect-shape_test", HasShape: false},
+			{Name: "Stringer", PkgPath: "fmt", HasShape: false},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Interface.Embedded(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("methods", func(t *testing.T) {
+		type method struct {
+			Name       string
+			Doc        string
+			DeclaredIn string
+			Recv       string
+			Args       []string
+		}
+		methods := iface.Methods()
+		funcs := iface.MethodFuncs()
+		var got []method
+		for i, m := range methods {
+			var args []string
+			for _, a := range funcs[i].Args() {
+				args = append(args, a.Name)
+			}
+			got = append(got, method{Name: m.Name, Doc: m.Doc, DeclaredIn: iface.DeclaredIn(m.Name).Name, Recv: funcs[i].Recv(), Args: args})
+		}
+		want := []method{
+			{Name: "Close", Doc: "Close closes.", DeclaredIn: "ReadWriter", Recv: "ReadWriter"},
+			{Name: "Read", Doc: "Read reads up to len(p) bytes into p.", DeclaredIn: "Reader", Recv: "ReadWriter", Args: []string{"p"}},
+			{Name: "String", Doc: "", DeclaredIn: "Stringer", Recv: "ReadWriter"},
+			{Name: "Write", Doc: "Write writes len(p) bytes from p.", DeclaredIn: "Writer", Recv: "ReadWriter", Args: []string{"p"}},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Interface.Methods(): -want, +got: \n%v", diff)
+		}
+		for _, fn := range funcs { // Recv() and Receiver() are the same interface
+			if want, got := fn.Recv(), fn.Receiver().Shape.Name; want != got {
+				t.Errorf("Interface.MethodFuncs()[%s].Receiver(): want:%v != got:%v", fn.Name(), want, got)
+			}
+		}
+	})
+}
+
+func TestFuncReceiver(t *testing.T) {
+	type result struct {
+		Name     string
+		TypeName string
+		Lv       int
+	}
+
+	t.Run("value", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+		cfg.Extract(S0{})
+
+		recv := cfg.Extract(new(S0).M).Func().Receiver()
+		want := result{Name: "s0", TypeName: "S0", Lv: 0}
+		if diff := cmp.Diff(want, result{Name: recv.Name, TypeName: recv.Shape.Name, Lv: recv.Shape.Lv}); diff != "" {
+			t.Errorf("Shape.Func().Receiver(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("pointer-without-metadata", func(t *testing.T) {
+		cfg := &reflectshape.Config{SkipComments: true}
+		cfg.Extract(S1{})
+
+		recv := cfg.Extract(new(S1).M).Func().Receiver()
+		want := result{Name: "", TypeName: "S1", Lv: 1}
+		if diff := cmp.Diff(want, result{Name: recv.Name, TypeName: recv.Shape.Name, Lv: recv.Shape.Lv}); diff != "" {
+			t.Errorf("Shape.Func().Receiver(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("value-not-extracted", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+
+		recv := cfg.Extract(new(S0).M).Func().Receiver() // the receiver type is unknown, but the source code is known
+		if want, got := "s0", recv.Name; want != got {
+			t.Errorf("Shape.Func().Receiver().Name: want:%v != got:%v", want, got)
+		}
+		if recv.Shape != nil {
+			t.Errorf("Shape.Func().Receiver().Shape: must be nil, but %v", recv.Shape)
+		}
+	})
+
+	t.Run("generic-value", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+		cfg.Extract(List[int]{})
+
+		recv := cfg.Extract(List[int]{}.Len).Func().Receiver()
+		want := result{Name: "l", TypeName: "List[int]", Lv: 0}
+		if diff := cmp.Diff(want, result{Name: recv.Name, TypeName: recv.Shape.Name, Lv: recv.Shape.Lv}); diff != "" {
+			t.Errorf("Shape.Func().Receiver(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("not-extracted", func(t *testing.T) {
+		cfg := &reflectshape.Config{SkipComments: true}
+		if recv := cfg.Extract(new(S1).M).Func().Receiver(); recv != nil {
+			t.Errorf("Shape.Func().Receiver(): must be nil, but %v", recv)
+		}
+	})
+
+	t.Run("function", func(t *testing.T) {
+		if recv := cfg.Extract(F0).Func().Receiver(); recv != nil {
+			t.Errorf("Shape.Func().Receiver(): must be nil, but %v", recv)
+		}
+	})
+
+	t.Run("interface-method", func(t *testing.T) {
+		iface := cfg.Extract(UseRepository).Func().Args()[0].Shape.Interface()
+		recv := iface.MethodFuncs()[0].Receiver()
+		if want, got := "Repository", recv.Shape.Name; want != got {
+			t.Errorf("Interface.MethodFuncs()[0].Receiver(): want:%v != got:%v", want, got)
+		}
+	})
+}
+
+type Service struct{}
+
+// Create creates something.
+func (s *Service) Create(ctx context.Context, name string) error { return nil }
+
+// Get gets something.
+func (s Service) Get(ctx context.Context, id int) (string, error) { return "", nil }
+
+func TestMethodExpression(t *testing.T) {
+	type result struct {
+		Name     string
+		IsMethod bool
+		Args     []string
+		Doc      string
+		Recv     string
+		RecvLv   int
+	}
+
+	cases := []struct {
+		msg  string
+		fn   any
+		want result
+	}{
+		{msg: "pointer", fn: (*Service).Create,
+			want: result{Name: "Service.Create", IsMethod: true, Args: []string{"ctx", "name"}, Doc: "Create creates something.", Recv: "Service", RecvLv: 1}},
+		{msg: "value", fn: Service.Get,
+			want: result{Name: "Service.Get", IsMethod: true, Args: []string{"ctx", "id"}, Doc: "Get gets something.", Recv: "Service", RecvLv: 0}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			shape := cfg.Extract(c.fn)
+			fn := shape.Func()
+			got := result{Name: shape.Name, IsMethod: fn.IsMethod(), Doc: fn.Doc()}
+			for _, a := range fn.Args() {
+				got.Args = append(got.Args, a.Name)
+			}
+			if recv := fn.Receiver(); recv != nil {
+				got.Recv = recv.Shape.Name
+				got.RecvLv = recv.Shape.Lv
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Shape.Func(): -want, +got: \n%v", diff)
+			}
+			if want, got := "github.com/podhmo/reflect-shape_test", shape.Package.Path; want != got {
+				t.Errorf("Shape.Package.Path: want:%v != got:%v", want, got)
+			}
+		})
+	}
+}
+
+// List is the generic list.
+type List[T any] struct {
+	items []T
+}
+
+// Push appends the value.
+func (l *List[T]) Push(v T) {}
+
+// Len returns the length.
+func (l List[T]) Len() (n int) { return len(l.items) }
+
+// Pair is the generic pair.
+type Pair[K comparable, V any] struct{}
+
+// Get returns the value.
+func (p Pair[K, V]) Get(key K) (value V, ok bool) { return }
+
+// Map converts the values.
+func Map[T, R any](xs []T, fn func(T) R) []R { return nil }
+
+func TestGenerics(t *testing.T) {
+	type result struct {
+		Name     string
+		IsMethod bool
+		Doc      string
+		Args     []string
+		Returns  []string
+		Recv     string
+	}
+
+	cases := []struct {
+		msg  string
+		fn   any
+		want result
+	}{
+		{msg: "func", fn: Map[int, string],
+			want: result{Name: "Map", Doc: "Map converts the values.", Args: []string{"xs", "fn"}, Returns: []string{""}}},
+		{msg: "method-expression-pointer", fn: (*List[int]).Push,
+			want: result{Name: "List.Push", IsMethod: true, Doc: "Push appends the value.", Args: []string{"v"}, Recv: "l"}},
+		{msg: "method-expression-value", fn: List[int].Len,
+			want: result{Name: "List.Len", IsMethod: true, Doc: "Len returns the length.", Returns: []string{"n"}, Recv: "l"}},
+		{msg: "method-expression-many-type-params", fn: Pair[string, int].Get,
+			want: result{Name: "Pair.Get", IsMethod: true, Doc: "Get returns the value.", Args: []string{"key"}, Returns: []string{"value", "ok"}, Recv: "p"}},
+		{msg: "method-value", fn: List[int]{}.Len,
+			want: result{Name: "List.Len", IsMethod: true, Doc: "Len returns the length.", Returns: []string{"n"}, Recv: "l"}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			shape := cfg.Extract(c.fn)
+			fn := shape.Func()
+			got := result{Name: shape.Name, IsMethod: fn.IsMethod(), Doc: fn.Doc()}
+			for _, a := range fn.Args() {
+				got.Args = append(got.Args, a.Name)
+			}
+			for _, a := range fn.Returns() {
+				got.Returns = append(got.Returns, a.Name)
+			}
+			if recv := fn.Receiver(); recv != nil {
+				got.Recv = recv.Name
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Shape.Func(): -want, +got: \n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package reflectshape
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change is the change of the API surface, found by Diff().
+type Change struct {
+	Kind     ChangeKind `json:"kind"`
+	Breaking bool       `json:"breaking"`
+	Name     string     `json:"name"` // e.g. "github.com/foo/bar.Person", "github.com/foo/bar.Person.Name"
+	Message  string     `json:"message"`
+
+	Doc      string `json:"doc,omitempty"`      // the doc of the changed element (for removed one, the old doc)
+	Position string `json:"position,omitempty"` // the position of the changed element (for removed one, the old position)
+}
+
+func (c *Change) String() string {
+	prefix := "non-breaking"
+	if c.Breaking {
+		prefix = "breaking"
+	}
+	return fmt.Sprintf("%s: %s %s: %s", prefix, c.Kind, c.Name, c.Message)
+}
+
+type ChangeList []*Change
+
+// Breaking returns the breaking changes only.
+func (cl ChangeList) Breaking() ChangeList {
+	var r ChangeList
+	for _, c := range cl {
+		if c.Breaking {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+func (cl ChangeList) String() string {
+	parts := make([]string, len(cl))
+	for i, c := range cl {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "\n")
+}
+
+// DiffConfig returns the changes of the API surface between the two configs (see Diff()).
+func DiffConfig(old, new *Config) ChangeList {
+	return Diff(old.Snapshot(), new.Snapshot())
+}
+
+// Diff returns the changes of the API surface from the old snapshot to the new snapshot.
+//
+// The following changes are breaking.
+//   - removing the shapes, the fields, the methods and the parameters
+//   - changing the kind, the type, the field tags and the signatures
+//   - adding the required parameters to funcs, and adding the methods to interfaces
+func Diff(old, new *Snapshot) ChangeList {
+	var r ChangeList
+	for _, o := range old.Shapes {
+		n := new.Lookup(o.Name)
+		if n == nil {
+			r = append(r, &Change{Kind: ChangeRemoved, Breaking: true, Name: o.Name, Message: fmt.Sprintf("%s is removed", o.Kind), Doc: o.Doc, Position: o.Position})
+			continue
+		}
+		r = append(r, diffShape(o, n)...)
+	}
+	for _, n := range new.Shapes {
+		if old.Lookup(n.Name) == nil {
+			r = append(r, &Change{Kind: ChangeAdded, Name: n.Name, Message: fmt.Sprintf("%s is added", n.Kind), Doc: n.Doc, Position: n.Position})
+		}
+	}
+	return r
+}
+
+func diffShape(o, n *ShapeSnapshot) ChangeList {
+	if o.Kind != n.Kind {
+		return ChangeList{{Kind: ChangeChanged, Breaking: true, Name: n.Name, Message: fmt.Sprintf("kind is changed, %s -> %s", o.Kind, n.Kind), Doc: n.Doc, Position: n.Position}}
+	}
+
+	var r ChangeList
+	switch o.Kind {
+	case reflect.Func.String():
+		r = append(r, diffArgs(o, n)...)
+		if ot, nt := varTypes(o.Returns), varTypes(n.Returns); ot != nt {
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: n.Name, Message: fmt.Sprintf("returns are changed, (%s) -> (%s)", ot, nt), Doc: n.Doc, Position: n.Position})
+		}
+	case reflect.Struct.String():
+		r = append(r, diffFields(o, n)...)
+	case reflect.Interface.String():
+		// only methods
+	default:
+		if o.Type != n.Type {
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: n.Name, Message: fmt.Sprintf("type is changed, %s -> %s", o.Type, n.Type), Doc: n.Doc, Position: n.Position})
+		}
+	}
+	r = append(r, diffMethods(o, n)...)
+	return r
+}
+
+func diffArgs(o, n *ShapeSnapshot) ChangeList {
+	var r ChangeList
+	if o.Variadic != n.Variadic && len(o.Args) == len(n.Args) {
+		r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: n.Name, Message: fmt.Sprintf("variadic is changed, %v -> %v", o.Variadic, n.Variadic), Doc: n.Doc, Position: n.Position})
+	}
+
+	for i, oa := range o.Args {
+		if i >= len(n.Args) {
+			r = append(r, &Change{Kind: ChangeRemoved, Breaking: true, Name: n.Name, Message: fmt.Sprintf("param#%d %s %s is removed", i, oa.Name, oa.Type), Doc: oa.Doc, Position: oa.Position})
+			continue
+		}
+		na := n.Args[i]
+		if oa.Type != na.Type {
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: n.Name, Message: fmt.Sprintf("param#%d type is changed, %s -> %s", i, oa.Type, na.Type), Doc: na.Doc, Position: na.Position})
+		} else if oa.Name != na.Name {
+			r = append(r, &Change{Kind: ChangeChanged, Name: n.Name, Message: fmt.Sprintf("param#%d is renamed, %s -> %s", i, oa.Name, na.Name), Doc: na.Doc, Position: na.Position})
+		}
+	}
+	for i := len(o.Args); i < len(n.Args); i++ {
+		na := n.Args[i]
+		if n.Variadic && !o.Variadic && i == len(n.Args)-1 { // f(x) -> f(x, ...opts) is compatible for callers
+			r = append(r, &Change{Kind: ChangeAdded, Name: n.Name, Message: fmt.Sprintf("variadic param#%d %s %s is added", i, na.Name, na.Type), Doc: na.Doc, Position: na.Position})
+			continue
+		}
+		r = append(r, &Change{Kind: ChangeAdded, Breaking: true, Name: n.Name, Message: fmt.Sprintf("new required param#%d %s %s is added", i, na.Name, na.Type), Doc: na.Doc, Position: na.Position})
+	}
+	return r
+}
+
+func diffFields(o, n *ShapeSnapshot) ChangeList {
+	var r ChangeList
+	for _, of := range o.Fields {
+		name := n.Name + "." + of.Name
+		nf := lookupVarSnapshot(n.Fields, of.Name)
+		if nf == nil {
+			r = append(r, &Change{Kind: ChangeRemoved, Breaking: true, Name: name, Message: "field is removed", Doc: of.Doc, Position: of.Position})
+			continue
+		}
+		if of.Type != nf.Type {
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: name, Message: fmt.Sprintf("field type is changed, %s -> %s", of.Type, nf.Type), Doc: nf.Doc, Position: nf.Position})
+		}
+		if of.Tag != nf.Tag { // the tags are used at runtime (e.g. encoding/json)
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: name, Message: fmt.Sprintf("field tag is changed, %q -> %q", of.Tag, nf.Tag), Doc: nf.Doc, Position: nf.Position})
+		}
+	}
+	for _, nf := range n.Fields {
+		if lookupVarSnapshot(o.Fields, nf.Name) == nil {
+			r = append(r, &Change{Kind: ChangeAdded, Name: n.Name + "." + nf.Name, Message: "field is added", Doc: nf.Doc, Position: nf.Position})
+		}
+	}
+	return r
+}
+
+func diffMethods(o, n *ShapeSnapshot) ChangeList {
+	var r ChangeList
+	for _, om := range o.Methods {
+		name := n.Name + "." + om.Name
+		nm := lookupVarSnapshot(n.Methods, om.Name)
+		if nm == nil {
+			r = append(r, &Change{Kind: ChangeRemoved, Breaking: true, Name: name, Message: "method is removed", Doc: om.Doc, Position: om.Position})
+			continue
+		}
+		if om.Type != nm.Type {
+			r = append(r, &Change{Kind: ChangeChanged, Breaking: true, Name: name, Message: fmt.Sprintf("method signature is changed, %s -> %s", om.Type, nm.Type), Doc: nm.Doc, Position: nm.Position})
+		}
+	}
+	for _, nm := range n.Methods {
+		if lookupVarSnapshot(o.Methods, nm.Name) == nil {
+			// adding methods to the interface breaks the existing implementations
+			breaking := n.Kind == reflect.Interface.String()
+			r = append(r, &Change{Kind: ChangeAdded, Breaking: breaking, Name: n.Name + "." + nm.Name, Message: "method is added", Doc: nm.Doc, Position: nm.Position})
+		}
+	}
+	return r
+}
+
+func lookupVarSnapshot(vars []*VarSnapshot, name string) *VarSnapshot {
+	for _, v := range vars {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func varTypes(vars []*VarSnapshot) string {
+	types := make([]string, len(vars))
+	for i, v := range vars {
+		types[i] = v.Type
+	}
+	return strings.Join(types, ", ")
+}
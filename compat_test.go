@@ -0,0 +1,98 @@
+package reflectshape_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// Customer is the customer.
+type Customer struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"` // name of customer
+	Email string `json:"email"`
+}
+
+func (c *Customer) Greet() string { return "hello " + c.Name }
+
+// RegisterCustomer registers the customer.
+func RegisterCustomer(ctx context.Context, c *Customer, tags ...string) error { return nil }
+
+type CustomerStore interface {
+	Get(ctx context.Context, id int) (*Customer, error)
+}
+
+func TestDiff(t *testing.T) {
+	newConfig := func() *reflectshape.Config {
+		c := &reflectshape.Config{IncludeGoTestFiles: true}
+		c.Extract(RegisterCustomer)
+		c.Extract((*CustomerStore)(nil))
+		return c
+	}
+
+	t.Run("no-changes", func(t *testing.T) {
+		if got := reflectshape.DiffConfig(newConfig(), newConfig()); len(got) != 0 {
+			t.Errorf("DiffConfig(): must be empty, but\n%v", got)
+		}
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		pkgpath := "github.com/podhmo/reflect-shape_test"
+		new := newConfig().Snapshot()
+
+		// the old snapshot is the serialized one
+		var old reflectshape.Snapshot
+		b, err := json.Marshal(new)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if err := json.Unmarshal(b, &old); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		customer := old.Lookup(pkgpath + ".Customer")
+		customer.Fields = []*reflectshape.VarSnapshot{
+			{Name: "ID", Type: "string", Tag: `json:"id"`},
+			{Name: "Name", Type: "string", Tag: `json:"fullname"`},
+			{Name: "Nickname", Type: "string"},
+		}
+		customer.Methods = append(customer.Methods, &reflectshape.VarSnapshot{Name: "Bye", Type: "() string"})
+		register := old.Lookup(pkgpath + ".RegisterCustomer")
+		register.Args = register.Args[:2]
+		register.Variadic = false
+		store := old.Lookup(pkgpath + ".CustomerStore")
+		store.Methods = nil
+		old.Shapes = append(old.Shapes, &reflectshape.ShapeSnapshot{Name: pkgpath + ".Supplier", Kind: "struct"})
+
+		got := reflectshape.Diff(&old, new)
+		want := []string{
+			"breaking: changed " + pkgpath + ".Customer.ID: field type is changed, string -> int",
+			`breaking: changed ` + pkgpath + `.Customer.Name: field tag is changed, "json:\"fullname\"" -> "json:\"name\""`,
+			"breaking: removed " + pkgpath + ".Customer.Nickname: field is removed",
+			"non-breaking: added " + pkgpath + ".Customer.Email: field is added",
+			"breaking: removed " + pkgpath + ".Customer.Bye: method is removed",
+			"breaking: added " + pkgpath + ".CustomerStore.Get: method is added",
+			"non-breaking: added " + pkgpath + ".RegisterCustomer: variadic param#2 tags []string is added",
+			"breaking: removed " + pkgpath + ".Supplier: struct is removed",
+		}
+		if diff := cmp.Diff(want, strings.Split(got.String(), "\n")); diff != "" {
+			t.Errorf("Diff(): -want, +got: \n%v", diff)
+		}
+		if want, got := 6, len(got.Breaking()); want != got {
+			t.Errorf("len(Diff().Breaking()): %d != %d", want, got)
+		}
+
+		// the change has the doc and the position of the field
+		c := got[1]
+		if want, got := "name of customer", c.Doc; want != got {
+			t.Errorf("Change.Doc: %q != %q", want, got)
+		}
+		if want, got := "compat_test.go:16:", c.Position; !strings.Contains(got, want) {
+			t.Errorf("Change.Position: %q is not contained in %q", want, got)
+		}
+	})
+}
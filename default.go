@@ -0,0 +1,91 @@
+package reflectshape
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type DefaultOptions struct {
+	Tag    string // the tag name of default values, default is "default" (e.g. `default:"8080"`)
+	UseDoc bool   // if true, the "Default: <value>" line in the field doc is also used (the tag is preferred)
+}
+
+// NewWithDefaults returns the new value (*T) of the struct shape, populated with the default values of the fields.
+// The pointers to the nested structs are allocated (except for the recursive ones), and the defaults are set recursively.
+func (s *Shape) NewWithDefaults(options DefaultOptions) (reflect.Value, error) {
+	rv := reflect.New(s.Type)
+	if err := s.SetDefaults(rv, options); err != nil {
+		return rv, err
+	}
+	return rv, nil
+}
+
+// SetDefaults sets the default values to the zero fields of rv (*T, the pointer of the struct shape's value).
+func (s *Shape) SetDefaults(rv reflect.Value, options DefaultOptions) error {
+	if s.Kind != reflect.Struct {
+		return fmt.Errorf("shape %v is not Struct kind, %s", s, s.Kind)
+	}
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Type() != s.Type {
+		return fmt.Errorf("unexpected value %v, *%v is expected", rv.Type(), s.Type)
+	}
+	if options.Tag == "" {
+		options.Tag = "default"
+	}
+	return setDefaults(s.Struct(), rv.Elem(), options, "", map[reflect.Type]bool{s.Type: true})
+}
+
+func setDefaults(s *Struct, rv reflect.Value, options DefaultOptions, prefix string, path map[reflect.Type]bool) error {
+	for i, f := range s.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name := prefix + f.Name
+		fv := rv.Field(i)
+
+		if v, ok := defaultValue(f, options); ok {
+			if !fv.IsZero() {
+				continue
+			}
+			if err := setString(fv, v); err != nil {
+				return fmt.Errorf("field %s: invalid default value %q, %w", name, v, err)
+			}
+			continue
+		}
+
+		// nested struct
+		if f.Shape.Kind != reflect.Struct || f.Shape.Lv > 1 || path[f.Shape.Type] {
+			continue
+		}
+		if f.Shape.Lv == 1 {
+			if fv.IsNil() {
+				fv.Set(reflect.New(f.Shape.Type))
+			}
+			fv = fv.Elem()
+		}
+		path[f.Shape.Type] = true
+		err := setDefaults(f.Shape.Struct(), fv, options, name+".", path)
+		delete(path, f.Shape.Type)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultValue returns the default value of the field from the tag or the doc.
+func defaultValue(f *Field, options DefaultOptions) (string, bool) {
+	if v, ok := f.Tag.Lookup(options.Tag); ok {
+		return v, true
+	}
+	if !options.UseDoc {
+		return "", false
+	}
+	for _, line := range strings.Split(f.Doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Default:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Default:")), true
+		}
+	}
+	return "", false
+}
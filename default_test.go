@@ -0,0 +1,112 @@
+package reflectshape_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+type ServerConfig struct {
+	Host string `default:"localhost"`
+	// Port of the server.
+	// Default: 8080
+	Port    int
+	Timeout time.Duration     `default:"5s"`
+	Tags    []string          `default:"a, b"`
+	Debug   *bool             `default:"true"`
+	Weights map[string]int    `default:"x=1,y=2"`
+	DB      *DBConfig         // allocated
+	Log     LogConfig         // nested
+	Parent  *ServerConfig     // recursive, not allocated
+	Extra   map[string]string // no default
+}
+
+type DBConfig struct {
+	URL      string `default:"postgres://localhost:5432"`
+	MaxConns int    `default:"10"`
+}
+
+type LogConfig struct {
+	Level string `default:"info"`
+}
+
+type AppConfig struct {
+	Name string `default:"foo" env-default:"bar"`
+}
+
+type BrokenConfig struct {
+	DB struct {
+		MaxConns int `default:"ten"`
+	}
+}
+
+func TestNewWithDefaults(t *testing.T) {
+	debug := true
+
+	t.Run("tag-and-doc", func(t *testing.T) {
+		rv, err := cfg.Extract(ServerConfig{}).NewWithDefaults(reflectshape.DefaultOptions{UseDoc: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := &ServerConfig{
+			Host:    "localhost",
+			Port:    8080,
+			Timeout: 5 * time.Second,
+			Tags:    []string{"a", "b"},
+			Debug:   &debug,
+			Weights: map[string]int{"x": 1, "y": 2},
+			DB:      &DBConfig{URL: "postgres://localhost:5432", MaxConns: 10},
+			Log:     LogConfig{Level: "info"},
+		}
+		if diff := cmp.Diff(want, rv.Interface()); diff != "" {
+			t.Errorf("Shape.NewWithDefaults(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("tag-only", func(t *testing.T) {
+		rv, err := cfg.Extract(ServerConfig{}).NewWithDefaults(reflectshape.DefaultOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if want, got := 0, rv.Interface().(*ServerConfig).Port; want != got {
+			t.Errorf("Shape.NewWithDefaults().Port: %v != %v", want, got)
+		}
+	})
+
+	t.Run("custom-tag", func(t *testing.T) {
+		rv, err := cfg.Extract(AppConfig{}).NewWithDefaults(reflectshape.DefaultOptions{Tag: "env-default"})
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if want, got := "bar", rv.Interface().(*AppConfig).Name; want != got {
+			t.Errorf("Shape.NewWithDefaults().Name: %q != %q", want, got)
+		}
+	})
+
+	t.Run("non-zero-fields-are-kept", func(t *testing.T) {
+		ob := &ServerConfig{Host: "example.com", DB: &DBConfig{MaxConns: 1}}
+		if err := cfg.Extract(ob).SetDefaults(reflect.ValueOf(ob), reflectshape.DefaultOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if want, got := "example.com", ob.Host; want != got {
+			t.Errorf("Host: %q != %q", want, got)
+		}
+		if diff := cmp.Diff(&DBConfig{URL: "postgres://localhost:5432", MaxConns: 1}, ob.DB); diff != "" {
+			t.Errorf("DB: -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("invalid-default", func(t *testing.T) {
+		_, err := cfg.Extract(BrokenConfig{}).NewWithDefaults(reflectshape.DefaultOptions{})
+		if err == nil {
+			t.Fatalf("error is expected, but nil")
+		}
+		if want, got := `field DB.MaxConns: invalid default value "ten"`, err.Error(); !strings.Contains(got, want) {
+			t.Errorf("error message: %q is not contained in %q", want, got)
+		}
+	})
+}
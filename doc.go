@@ -0,0 +1,120 @@
+package reflectshape
+
+import (
+	"go/doc/comment"
+	"sort"
+	"strings"
+)
+
+// Doc is the parsed doc comment (see go/doc/comment).
+// Doc links are resolved to the shapes if they are already extracted, the symbols only declared in the package are also links.
+type Doc struct {
+	*comment.Doc
+	Shape *Shape // the owner of this doc comment
+}
+
+func (s *Shape) parseDoc(text string) *Doc {
+	p := &comment.Parser{
+		LookupPackage: s.e.lookupPackageByName,
+		LookupSym: func(recv, name string) bool {
+			if s.Package.scope.Lookup(symbolName(recv, name)) != nil {
+				return true
+			}
+			// not extracted yet, but declared in the package
+			return s.e.Lookup != nil && s.Package.Path != "" && s.e.Lookup.LookupSymbol(s.Package.Path, recv, name)
+		},
+	}
+	return &Doc{Doc: p.Parse(text), Shape: s}
+}
+
+// lookupPackageByName returns the import path of the extracted package by name (e.g. "http" -> "net/http").
+// The package whose path is the name itself is preferred, then the name guessed from the path is matched (without I/O),
+// and only if it is ambiguous or not found, the name declared in the package clause is used.
+func (e *Extractor) lookupPackageByName(name string) (importPath string, ok bool) {
+	if _, ok := e.packages[name]; ok && name != "" {
+		return name, true
+	}
+	paths := make([]string, 0, len(e.packages))
+	for path := range e.packages {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var guessed []string
+	for _, path := range paths {
+		if e.packages[path].Name == name {
+			guessed = append(guessed, path)
+		}
+	}
+	if len(guessed) == 1 {
+		return guessed[0], true
+	}
+	candidates := guessed
+	if len(candidates) == 0 {
+		candidates = paths
+	}
+	for _, path := range candidates {
+		if e.packages[path].DeclaredName() == name {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// LookupLink returns the shape of the doc link's target. If the target is not extracted yet, returns nil.
+func (d *Doc) LookupLink(link *comment.DocLink) *Shape {
+	pkg := d.Shape.Package
+	if link.ImportPath != "" {
+		pkg = d.Shape.e.packages[link.ImportPath]
+		if pkg == nil {
+			return nil
+		}
+	}
+	return pkg.scope.Lookup(symbolName(link.Recv, link.Name))
+}
+
+// Printer returns the printer, the links to the same package's shapes are rendered as fragment (e.g. "#Name").
+func (d *Doc) Printer() *comment.Printer {
+	baseURL := d.Shape.e.Config.DocLinkBaseURL
+	return &comment.Printer{
+		DocLinkURL: func(link *comment.DocLink) string {
+			if target := d.LookupLink(link); target != nil && target.Package == d.Shape.Package {
+				return "#" + target.Name
+			}
+			return link.DefaultURL(baseURL)
+		},
+	}
+}
+
+func (d *Doc) Markdown() string {
+	return string(d.Printer().Markdown(d.Doc))
+}
+
+func (d *Doc) HTML() string {
+	return string(d.Printer().HTML(d.Doc))
+}
+
+func (d *Doc) Text() string {
+	return string(d.Printer().Text(d.Doc))
+}
+
+// deprecation returns the reason text of the "Deprecated: " paragraph in doc, it is used by the Deprecated() of the views (e.g. Struct, Field).
+func deprecation(doc string) (reason string, ok bool) {
+	const marker = "Deprecated: "
+	for _, paragraph := range strings.Split(doc, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if strings.HasPrefix(paragraph, marker) {
+			return strings.Join(strings.Fields(paragraph[len(marker):]), " "), true
+		}
+	}
+	return "", false
+}
+
+func symbolName(recv, name string) string {
+	if recv == "" {
+		return name
+	}
+	return recv + "." + name
+}
@@ -0,0 +1,144 @@
+package reflectshape_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// Team is the group of [Person].
+//
+// # Usage
+//
+// Members are:
+//   - leader
+//   - others
+//
+// Use with [context.Context].
+//
+//	team := Team{}
+type Team struct {
+	Leader  *Person
+	Members []*Person // the members, see [Person]
+}
+
+func TestDocComment(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	cfg.Extract(Person{})
+
+	s := cfg.Extract(Team{}).Struct()
+	doc := s.DocComment()
+
+	t.Run("markdown", func(t *testing.T) {
+		want := `Team is the group of [Person](#Person).
+
+### Usage {#hdr-Usage}
+
+Members are:
+
+  - leader
+  - others
+
+Use with [context.Context](https://pkg.go.dev/context#Context).
+
+	team := Team{}
+`
+		if diff := cmp.Diff(want, doc.Markdown()); diff != "" {
+			t.Errorf("Struct.DocComment().Markdown(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		want := "Team is the group of Person.\n"
+		got := doc.Text()
+		if !strings.HasPrefix(got, want) {
+			t.Errorf("Struct.DocComment().Text(): want prefix %q, but got %q", want, got)
+		}
+	})
+
+	t.Run("field", func(t *testing.T) {
+		want := "the members, see [Person](#Person)\n"
+		got := s.Fields()[1].DocComment().Markdown()
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Field.DocComment().Markdown(): -want, +got: \n%v", diff)
+		}
+	})
+}
+
+// Roster is the list of [Team] members, created by [NewLegacyTeam], see also [Customer.Greet], [Team.Leader] and [Undeclared].
+type Roster struct{}
+
+func TestDocCommentNotExtracted(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	doc := cfg.Extract(Roster{}).Struct().DocComment() // Team and NewLegacyTeam are not extracted
+
+	want := "Roster is the list of [Team](#Team) members, created by [NewLegacyTeam](#NewLegacyTeam), see also [Customer.Greet](#Customer.Greet), \\[Team.Leader] and \\[Undeclared].\n" // fields and undeclared symbols are not links
+	if diff := cmp.Diff(want, doc.Markdown()); diff != "" {
+		t.Errorf("Struct.DocComment().Markdown(): -want, +got: \n%v", diff)
+	}
+}
+
+// LegacyTeam is the old version of [Team].
+//
+// Deprecated: use [Team] instead,
+// this will be removed.
+type LegacyTeam struct {
+	// Leader of team.
+	//
+	// Deprecated: use Members[0].
+	Leader  *Person
+	Members []*Person
+}
+
+// NewLegacyTeam creates LegacyTeam.
+//
+// Deprecated: no longer supported.
+func NewLegacyTeam() *LegacyTeam {
+	return nil
+}
+
+func TestDeprecated(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+
+	type result struct {
+		Reason     string
+		Deprecated bool
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		s := cfg.Extract(LegacyTeam{}).Struct()
+		reason, ok := s.Deprecated()
+		want := result{Reason: "use [Team] instead, this will be removed.", Deprecated: true}
+		if diff := cmp.Diff(want, result{Reason: reason, Deprecated: ok}); diff != "" {
+			t.Errorf("Struct.Deprecated(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("field", func(t *testing.T) {
+		var got []result
+		for _, f := range cfg.Extract(LegacyTeam{}).Struct().Fields() {
+			reason, ok := f.Deprecated()
+			got = append(got, result{Reason: reason, Deprecated: ok})
+		}
+		want := []result{{Reason: "use Members[0].", Deprecated: true}, {}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Field.Deprecated(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("func", func(t *testing.T) {
+		reason, ok := cfg.Extract(NewLegacyTeam).Func().Deprecated()
+		want := result{Reason: "no longer supported.", Deprecated: true}
+		if diff := cmp.Diff(want, result{Reason: reason, Deprecated: ok}); diff != "" {
+			t.Errorf("Func.Deprecated(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("not-deprecated", func(t *testing.T) {
+		if _, ok := cfg.Extract(Team{}).Struct().Deprecated(); ok {
+			t.Errorf("Struct.Deprecated(): must not be deprecated")
+		}
+	})
+}
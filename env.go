@@ -0,0 +1,207 @@
+package reflectshape
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type EnvOptions struct {
+	Tag    string                      // the tag name of environment variable names, default is "env" (e.g. `env:"PORT"`, `env:"-"` is skipped)
+	Prefix string                      // the prefix of environment variable names (e.g. "APP_")
+	Lookup func(string) (string, bool) // default is os.LookupEnv
+}
+
+// LoadEnv fills the fields of ptr (*T, the pointer of the struct shape's value) from the environment variables.
+//
+// The names are the prefix and the field path in SCREAMING_SNAKE_CASE (e.g. "APP_DB_MAX_CONNS"), a tag value replaces the name of the field.
+// The fields not found in the environment are kept, and the pointers to nested structs are allocated only if some of their fields are found.
+func (s *Shape) LoadEnv(ptr interface{}, options EnvOptions) error {
+	rv, err := s.structValue(ptr)
+	if err != nil {
+		return err
+	}
+	if options.Tag == "" {
+		options.Tag = "env"
+	}
+	if options.Lookup == nil {
+		options.Lookup = os.LookupEnv
+	}
+	_, err = loadEnv(s.Struct(), rv, options, options.Prefix, map[reflect.Type]bool{s.Type: true})
+	return err
+}
+
+func loadEnv(s *Struct, rv reflect.Value, options EnvOptions, prefix string, path map[reflect.Type]bool) (int, error) {
+	loaded := 0
+	for _, f := range envFields(s, options, prefix, path) {
+		fv := rv.Field(f.index)
+		if f.nested == nil {
+			v, ok := options.Lookup(f.name)
+			if !ok {
+				continue
+			}
+			if err := setString(fv, v); err != nil {
+				return loaded, fmt.Errorf("env %s: invalid value %q, %w", f.name, v, err)
+			}
+			loaded++
+			continue
+		}
+
+		target := fv
+		if f.Shape.Lv == 1 {
+			target = reflect.New(f.Shape.Type).Elem()
+			if !fv.IsNil() {
+				target = fv.Elem()
+			}
+		}
+		path[f.Shape.Type] = true
+		n, err := loadEnv(f.nested, target, options, f.name, path)
+		delete(path, f.Shape.Type)
+		if err != nil {
+			return loaded, err
+		}
+		if n > 0 && f.Shape.Lv == 1 && fv.IsNil() {
+			fv.Set(target.Addr())
+		}
+		loaded += n
+	}
+	return loaded, nil
+}
+
+// WriteEnvExample writes the .env.example of the struct shape, the field docs are written as comments.
+// The values of ob (T or *T) are written as the example values, if ob is nil, the values are empty.
+func (s *Shape) WriteEnvExample(w io.Writer, ob interface{}, options EnvOptions) error {
+	rv, err := s.exampleValue(ob)
+	if err != nil {
+		return err
+	}
+	if options.Tag == "" {
+		options.Tag = "env"
+	}
+
+	st := s.Struct()
+	if doc := st.Doc(); strings.TrimSpace(doc) != "" {
+		writeComment(w, "", doc)
+		fmt.Fprintln(w, "")
+	}
+	return writeEnvExample(w, st, rv, options, options.Prefix, map[reflect.Type]bool{s.Type: true})
+}
+
+func writeEnvExample(w io.Writer, s *Struct, rv reflect.Value, options EnvOptions, prefix string, path map[reflect.Type]bool) error {
+	for _, f := range envFields(s, options, prefix, path) {
+		fv := rv.Field(f.index)
+		if f.nested == nil {
+			writeComment(w, "", f.Doc)
+			v := formatValue(fv)
+			if strings.ContainsAny(v, " \t\n#\"'") {
+				v = strconv.Quote(v)
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s\n", f.name, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.Shape.Lv == 1 {
+			if fv.IsNil() {
+				fv = reflect.New(f.Shape.Type)
+			}
+			fv = fv.Elem()
+		}
+		path[f.Shape.Type] = true
+		err := writeEnvExample(w, f.nested, fv, options, f.name, path)
+		delete(path, f.Shape.Type)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeComment writes the doc as "# " comments (for .env, YAML and TOML).
+func writeComment(w io.Writer, indent string, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintln(w, indent+strings.TrimRight("# "+line, " "))
+	}
+}
+
+// envField is the field bound to the environment variable (or the nested struct).
+type envField struct {
+	*Field
+	index  int
+	name   string  // the environment variable name (for nested struct, the prefix)
+	nested *Struct // if not nil, the field is the nested struct
+}
+
+func envFields(s *Struct, options EnvOptions, prefix string, path map[reflect.Type]bool) []envField {
+	var r []envField
+	for i, f := range s.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name, ok := f.Tag.Lookup(options.Tag)
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = screamingSnakeCase(f.Name)
+		}
+
+		if f.Shape.Kind == reflect.Struct && f.Shape.Lv <= 1 && !canSetString(f.Type) {
+			if path[f.Shape.Type] {
+				continue // recursive
+			}
+			nestedPrefix := prefix + name + "_"
+			if f.Anonymous {
+				nestedPrefix = prefix
+			}
+			r = append(r, envField{Field: f, index: i, name: nestedPrefix, nested: f.Shape.Struct()})
+			continue
+		}
+		if !canSetString(f.Type) {
+			continue
+		}
+		r = append(r, envField{Field: f, index: i, name: prefix + name})
+	}
+	return r
+}
+
+// structValue returns the struct value of ptr (*T, the pointer of the struct shape's value).
+func (s *Shape) structValue(ptr interface{}) (reflect.Value, error) {
+	if s.Kind != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("shape %v is not Struct kind, %s", s, s.Kind)
+	}
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Type() != s.Type {
+		return reflect.Value{}, fmt.Errorf("unexpected value %T, *%v is expected", ptr, s.Type)
+	}
+	return rv.Elem(), nil
+}
+
+// exampleValue returns the struct value of ob (T or *T, the value of the struct shape), if ob is nil, returns the zero value.
+func (s *Shape) exampleValue(ob interface{}) (reflect.Value, error) {
+	if s.Kind != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("shape %v is not Struct kind, %s", s, s.Kind)
+	}
+	if ob == nil {
+		return reflect.New(s.Type).Elem(), nil
+	}
+	rv := reflect.ValueOf(ob)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return reflect.New(s.Type).Elem(), nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() != s.Type {
+		return reflect.Value{}, fmt.Errorf("unexpected value %T, %v or *%v is expected", ob, s.Type, s.Type)
+	}
+	return rv, nil
+}
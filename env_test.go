@@ -0,0 +1,105 @@
+package reflectshape_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// WorkerConfig is the configuration of the worker.
+type WorkerConfig struct {
+	// Name of the worker.
+	Name        string
+	Concurrency int `env:"WORKERS"` // the number of workers
+	Interval    time.Duration
+	Queues      []string
+	DB          *DBConfig
+	Log         LogConfig
+	Token       string `env:"-"`
+}
+
+func TestLoadEnv(t *testing.T) {
+	lookup := func(env map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		}
+	}
+
+	t.Run("load", func(t *testing.T) {
+		env := map[string]string{
+			"APP_NAME":      "w1",
+			"APP_WORKERS":   "4",
+			"APP_INTERVAL":  "1m",
+			"APP_QUEUES":    "high,low",
+			"APP_LOG_LEVEL": "debug",
+			"APP_TOKEN":     "xxx", // skipped
+		}
+		ob := &WorkerConfig{Name: "default", Concurrency: 1}
+		if err := cfg.Extract(ob).LoadEnv(ob, reflectshape.EnvOptions{Prefix: "APP_", Lookup: lookup(env)}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := &WorkerConfig{
+			Name:        "w1",
+			Concurrency: 4,
+			Interval:    time.Minute,
+			Queues:      []string{"high", "low"},
+			DB:          nil, // not allocated
+			Log:         LogConfig{Level: "debug"},
+		}
+		if diff := cmp.Diff(want, ob); diff != "" {
+			t.Errorf("Shape.LoadEnv(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("nested-pointer", func(t *testing.T) {
+		env := map[string]string{"DB_MAX_CONNS": "3"}
+		ob := &WorkerConfig{}
+		if err := cfg.Extract(ob).LoadEnv(ob, reflectshape.EnvOptions{Lookup: lookup(env)}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if diff := cmp.Diff(&DBConfig{MaxConns: 3}, ob.DB); diff != "" {
+			t.Errorf("Shape.LoadEnv().DB: -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		env := map[string]string{"WORKERS": "four"}
+		ob := &WorkerConfig{}
+		err := cfg.Extract(ob).LoadEnv(ob, reflectshape.EnvOptions{Lookup: lookup(env)})
+		if err == nil {
+			t.Fatalf("error is expected, but nil")
+		}
+		if want, got := `env WORKERS: invalid value "four"`, err.Error(); !strings.Contains(got, want) {
+			t.Errorf("error message: %q is not contained in %q", want, got)
+		}
+	})
+}
+
+func TestWriteEnvExample(t *testing.T) {
+	ob := &WorkerConfig{Name: "my worker", Concurrency: 2, Interval: 30 * time.Second, Queues: []string{"default"}}
+
+	var buf bytes.Buffer
+	if err := cfg.Extract(ob).WriteEnvExample(&buf, ob, reflectshape.EnvOptions{Prefix: "APP_"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	want := `# WorkerConfig is the configuration of the worker.
+
+# Name of the worker.
+APP_NAME="my worker"
+# the number of workers
+APP_WORKERS=2
+APP_INTERVAL=30s
+APP_QUEUES=default
+APP_DB_URL=
+APP_DB_MAX_CONNS=0
+APP_LOG_LEVEL=
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Shape.WriteEnvExample(): -want, +got: \n%v", diff)
+	}
+}
@@ -1,12 +1,13 @@
 package reflectshape
 
 import (
-	"fmt"
+	"go/token"
 	"reflect"
 	"runtime"
 	"sort"
 	"strings"
 
+	"github.com/podhmo/reflect-shape/internal/symbol"
 	"github.com/podhmo/reflect-shape/metadata"
 )
 
@@ -16,10 +17,110 @@ type Extractor struct {
 
 	seen     map[ID]*Shape
 	packages map[string]*Package
+
+	querying  int           // if > 0, the extracted shapes are not registered (e.g. in Reachable, Snapshot)
+	transient map[ID]*Shape // the shapes extracted while querying, registered when they are extracted outside of queries
+	number    int           // the next Shape.Number
+}
+
+// query starts the query, the shapes extracted until the returned function is called are not registered as visited.
+//
+//	defer e.query()()
+func (e *Extractor) query() func() {
+	if e.transient == nil {
+		e.transient = map[ID]*Shape{}
+	}
+	e.querying++
+	return func() { e.querying-- }
 }
 
+// Visited returns the copy of the visited shapes.
 func (e *Extractor) Visited() map[ID]*Shape {
-	return e.seen
+	r := make(map[ID]*Shape, len(e.seen))
+	for k, v := range e.seen {
+		r[k] = v
+	}
+	return r
+}
+
+// VisitedShapes returns the visited shapes, in the order of extraction (Shape.Number).
+func (e *Extractor) VisitedShapes() []*Shape {
+	r := make([]*Shape, 0, len(e.seen))
+	for _, s := range e.seen {
+		r = append(r, s)
+	}
+	sortByNumber(r)
+	return r
+}
+
+// Reachable returns the shapes reachable from the roots (including the roots), in the order of extraction (Shape.Number).
+// The shapes of fields, arguments, return values, interface methods and the elements of the containers are followed.
+// The shapes found only by the walk are not registered as visited.
+func (e *Extractor) Reachable(roots ...*Shape) []*Shape {
+	defer e.query()()
+
+	seen := map[ID]bool{}
+	var r []*Shape
+	var walk func(s *Shape)
+	walk = func(s *Shape) {
+		if s == nil || seen[s.ID] {
+			return
+		}
+		seen[s.ID] = true
+		if canonical, ok := e.seen[s.ID]; ok {
+			s = canonical // Lv=0
+		} else if canonical, ok := e.transient[s.ID]; ok {
+			s = canonical // Lv=0
+		}
+		r = append(r, s)
+		for _, rt := range e.neighbors(s) {
+			walk(e.extract(rt, rzero(rt)))
+		}
+	}
+	for _, s := range roots {
+		walk(s)
+	}
+	sortByNumber(r)
+	return r
+}
+
+// neighbors returns the types directly referenced by the shape.
+func (e *Extractor) neighbors(s *Shape) []reflect.Type {
+	rt := s.Type
+	var r []reflect.Type
+	switch rt.Kind() {
+	case reflect.Struct:
+		for i, n := 0, rt.NumField(); i < n; i++ {
+			r = append(r, rt.Field(i).Type)
+		}
+	case reflect.Func:
+		for i, n := 0, rt.NumIn(); i < n; i++ {
+			r = append(r, rt.In(i))
+		}
+		for i, n := 0, rt.NumOut(); i < n; i++ {
+			r = append(r, rt.Out(i))
+		}
+	case reflect.Interface:
+		for i, n := 0, rt.NumMethod(); i < n; i++ {
+			r = append(r, rt.Method(i).Type)
+		}
+	case reflect.Map:
+		r = append(r, rt.Key(), rt.Elem())
+	case reflect.Slice, reflect.Array, reflect.Chan:
+		r = append(r, rt.Elem())
+	}
+	return r
+}
+
+func sortByNumber(shapes []*Shape) {
+	sort.Slice(shapes, func(i, j int) bool { return shapes[i].Number < shapes[j].Number })
+}
+
+func (e *Extractor) position(pos token.Pos) token.Position {
+	if !pos.IsValid() || e.Config.Fset == nil {
+		return token.Position{}
+	}
+	return e.Config.Fset.Position(pos)
 }
 
 func (e *Extractor) Extract(ob interface{}) *Shape {
@@ -43,6 +144,12 @@ func (e *Extractor) extract(rt reflect.Type, rv reflect.Value) *Shape {
 	}
 
 	shape, ok := e.seen[id]
+	if !ok {
+		if shape, ok = e.transient[id]; ok && e.querying == 0 {
+			delete(e.transient, id)
+			e.register(shape)
+		}
+	}
 	if ok {
 		if lv == 0 {
 			return shape
@@ -57,32 +164,19 @@ func (e *Extractor) extract(rt reflect.Type, rv reflect.Value) *Shape {
 	isMethod := false
 
 	if id.pc != 0 { // is function?
-		fullname := runtime.FuncForPC(id.pc).Name()
-		parts := strings.Split(fullname, ".")
-
-		if strings.HasSuffix(fullname, "-fm") {
-			isMethod = true
-			// @@ github.com/podhmo/reflect-shape/neo_test.S0.M-fm
-			// @@ github.com/podhmo/reflect-shape/neo_test.(*S1).M-fm
-			pkgPath = strings.Join(parts[:len(parts)-2], ".")
-			name = fmt.Sprintf("%s.%s", strings.Trim(parts[len(parts)-2], "(*)"), strings.TrimSuffix(parts[len(parts)-1], "-fm"))
-		} else {
-			// @@ github.com/podhmo/reflect-shape/neo_test.F1
-			// @@ github.com/podhmo/reflect-shape/neo_test.S0
-			pkgPath = strings.Join(parts[:len(parts)-1], ".")
-			name = parts[len(parts)-1]
-		}
+		// @@ github.com/podhmo/reflect-shape/neo_test.F1
+		// @@ github.com/podhmo/reflect-shape/neo_test.S0.M-fm (method value)
+		// @@ github.com/podhmo/reflect-shape/neo_test.(*S1).M (method expression)
+		// @@ github.com/podhmo/reflect-shape/neo_test.F1.func1 (anonymous function)
+		sym := symbol.Parse(runtime.FuncForPC(id.pc).Name())
+		pkgPath = sym.PkgPath
+		name = sym.Qualified()
+		isMethod = !sym.IsClosure() && (sym.IsMethodValue || (sym.IsMethod() && isMethodExpression(rt, sym.PkgPath, sym.Recv)))
 	}
 
 	pkg, ok := e.packages[pkgPath]
 	if !ok {
-		parts := strings.Split(pkgPath, "/") // todo fix
-		pkgName := parts[len(parts)-1]
-		pkg = &Package{
-			Name:  pkgName,
-			Path:  pkgPath,
-			scope: &Scope{shapes: map[string]*Shape{}},
-		}
+		pkg = e.newPackage(pkgPath)
 		e.packages[pkgPath] = pkg
 	}
 
@@ -92,13 +186,17 @@ func (e *Extractor) extract(rt reflect.Type, rv reflect.Value) *Shape {
 		ID:           id,
 		Type:         rt,
 		DefaultValue: rv,
-		Number:       len(e.seen),
+		Number:       e.number,
 		IsMethod:     isMethod,
 		Package:      pkg,
 		e:            e,
 	}
-	e.seen[id] = shape
-	pkg.scope.shapes[name] = shape
+	e.number++
+	if e.querying > 0 {
+		e.transient[id] = shape
+	} else {
+		e.register(shape)
+	}
 
 	if lv == 0 {
 		return shape
@@ -108,13 +206,130 @@ func (e *Extractor) extract(rt reflect.Type, rv reflect.Value) *Shape {
 	return &copied
 }
 
+// register registers the shape as visited, and adds it to the scope of its package.
+func (e *Extractor) register(s *Shape) {
+	e.seen[s.ID] = s
+	key := s.Name
+	if s.symbol().IsMethodValue {
+		key = s.Name + methodValueSuffix // not to overwrite the method expression with the same name
+	}
+	s.Package.scope.shapes[key] = s
+}
+
+func (e *Extractor) newPackage(pkgPath string) *Package {
+	return &Package{
+		Name:   metadata.GuessPackageName(pkgPath),
+		Path:   pkgPath,
+		lookup: e.Lookup,
+		scope:  &Scope{shapes: map[string]*Shape{}},
+	}
+}
+
+// isMethodExpression reports whether the func type is the type of method expression (e.g. (*T).M), the first argument is the receiver.
+func isMethodExpression(rt reflect.Type, pkgPath string, recvName string) bool {
+	if rt.NumIn() == 0 {
+		return false
+	}
+	recv := rt.In(0)
+	if recv.Kind() == reflect.Pointer {
+		recv = recv.Elem()
+	}
+	name, _, _ := strings.Cut(recv.Name(), "[") // for generics
+	return name == recvName && recv.PkgPath() == pkgPath
+}
+
+// receiverOf returns the receiver shape of the method shape.
+// For method expressions, it is the first argument. Otherwise, it is found from the extracted shapes by the receiver type name,
+// the type arguments are ignored (e.g. "List" matches "List[int]"), but if the type is instantiated with several type arguments, it is ambiguous.
+func (e *Extractor) receiverOf(s *Shape) *Shape {
+	if s.isMethodExpression() {
+		rt := s.Type.In(0)
+		return e.extract(rt, rzero(rt))
+	}
+	if s.ID.pc == 0 {
+		return nil
+	}
+
+	// @@ github.com/podhmo/reflect-shape/neo_test.(*S1).M-fm
+	// @@ github.com/podhmo/reflect-shape/neo_test.List[...].Len-fm
+	sym := s.symbol()
+	var recv *Shape
+	for name, shape := range s.Package.scope.shapes {
+		if shape.IsMethod || shape.ID.pc != 0 {
+			continue
+		}
+		if name, _, _ := strings.Cut(name, "["); name != sym.Recv {
+			continue
+		}
+		if recv != nil {
+			return nil // ambiguous (e.g. List[int] and List[string])
+		}
+		recv = shape
+	}
+	if recv == nil || !sym.IsPointer {
+		return recv
+	}
+	copied := *recv
+	copied.Lv = 1
+	return &copied
+}
+
 type Package struct {
-	Name string
+	Name string // the name guessed from the package path (e.g. "yaml" for "gopkg.in/yaml.v3"), see also DeclaredName()
 	Path string
 
+	declaredName string
+	dir          string
+	module       *Module
+	resolved     bool
+	lookup       *metadata.Lookup
+
 	scope *Scope
 }
 
+// DeclaredName returns the name declared in the package clause. If the source code is not available, returns Name.
+func (p *Package) DeclaredName() string {
+	p.resolve()
+	return p.declaredName
+}
+
+// Dir returns the directory containing the package's source files, if the source code is available.
+func (p *Package) Dir() string {
+	p.resolve()
+	return p.dir
+}
+
+// Module returns the module that the package belongs to, nil if the package is not in module (e.g. standard library).
+// If the source code is not available, the module is found from the build information.
+func (p *Package) Module() *Module {
+	p.resolve()
+	return p.module
+}
+
+// resolve loads the package information on demand, because it requires `go list`.
+func (p *Package) resolve() {
+	if p.resolved {
+		return
+	}
+	p.resolved = true
+	if p.Path == "" {
+		return
+	}
+	if p.lookup != nil {
+		if pkg, err := p.lookup.LookupPackage(p.Path); err == nil {
+			p.declaredName = pkg.Name
+			p.dir = pkg.Dir
+			p.module = pkg.Module
+			return
+		}
+	}
+	p.declaredName = p.Name
+	p.module = metadata.ModuleFromBuildInfo(p.Path)
+}
+
+// Module is the module that the package belongs to.
+type Module = metadata.Module
+
 func (p *Package) Scope() *Scope {
 	return p.scope
 }
@@ -123,6 +338,39 @@ type Scope struct {
 	shapes map[string]*Shape
 }
 
+// methodValueSuffix is the suffix of the scope key of method values (e.g. "S.M-fm"), the method expressions have the same name.
+const methodValueSuffix = "-fm"
+
+// Lookup returns the shape by name (e.g. "S", "S.M", "F.func1"), if not found, returns nil.
+// If both the method expression and the method value are extracted, the method expression is returned.
+func (s *Scope) Lookup(name string) *Shape {
+	if shape, ok := s.shapes[name]; ok {
+		return shape
+	}
+	return s.shapes[name+methodValueSuffix]
+}
+
+// Shapes returns the shapes that satisfy all filters, sorted by name (and by Shape.Number, if the names are same).
+func (s *Scope) Shapes(filters ...ShapeFilter) []*Shape {
+	r := make([]*Shape, 0, len(s.shapes))
+toplevel:
+	for _, shape := range s.shapes {
+		for _, filter := range filters {
+			if !filter(shape) {
+				continue toplevel
+			}
+		}
+		r = append(r, shape)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Name == r[j].Name {
+			return r[i].Number < r[j].Number // e.g. the method expression and the method value
+		}
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
+
 func (s *Scope) Names() []string {
 	return s.names(false)
 }
@@ -132,14 +380,54 @@ func (s *Scope) NamesWithMethod() []string {
 }
 
 func (s *Scope) names(withMethod bool) []string {
-	// anonymous function is not supported yet
-	r := make([]string, 0, len(s.shapes))
-	for name, s := range s.shapes {
-		if !withMethod && s.IsMethod {
+	// anonymous function has the synthetic name from the enclosing function (e.g. "F.func1", "S.M.func1.2")
+	var filters []ShapeFilter
+	if !withMethod {
+		filters = append(filters, FilterMethod(false))
+	}
+	shapes := s.Shapes(filters...)
+	r := make([]string, 0, len(shapes))
+	for _, shape := range shapes {
+		if len(r) > 0 && r[len(r)-1] == shape.Name {
 			continue
 		}
-		r = append(r, name)
+		r = append(r, shape.Name)
 	}
-	sort.Strings(r)
 	return r
 }
+
+// ShapeFilter is the predicate for Scope.Shapes().
+type ShapeFilter func(*Shape) bool
+
+// FilterKind selects the shapes of the kinds.
+func FilterKind(kinds ...reflect.Kind) ShapeFilter {
+	return func(s *Shape) bool {
+		for _, k := range kinds {
+			if s.Kind == k {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterExported selects the exported (or unexported) shapes. Anonymous functions are treated as unexported.
+func FilterExported(exported bool) ShapeFilter {
+	return func(s *Shape) bool {
+		return s.IsExported() == exported
+	}
+}
+
+// FilterMethod selects the methods (or non-methods).
+func FilterMethod(isMethod bool) ShapeFilter {
+	return func(s *Shape) bool {
+		return s.IsMethod == isMethod
+	}
+}
+
+// FilterAnonymous selects the anonymous functions (or the others).
+func FilterAnonymous(anonymous bool) ShapeFilter {
+	return func(s *Shape) bool {
+		return s.IsAnonymous() == anonymous
+	}
+}
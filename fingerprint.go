@@ -0,0 +1,101 @@
+package reflectshape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strconv"
+)
+
+type FingerprintOptions struct {
+	IncludeDocs        bool // if true, the docs of the types, fields and methods are also hashed
+	IgnorePointerLevel bool // if true, *T and T are treated as same
+}
+
+// Fingerprint returns the hash of the structure reachable from the shape (field names, types, tags and method signatures).
+// The value is changed only when the structure is changed, so it can be used for invalidating the caches or the generated code.
+func (s *Shape) Fingerprint() string {
+	return s.FingerprintWith(FingerprintOptions{})
+}
+
+// FingerprintWith is the version of Fingerprint() with options.
+func (s *Shape) FingerprintWith(options FingerprintOptions) string {
+	defer s.e.query()()
+
+	w := &typeWriter{ignorePointer: options.IgnorePointerLevel, seen: map[reflect.Type]bool{}}
+	if s.Type.Name() != "" {
+		w.seen[s.Type] = true // recursive type
+	}
+
+	w.writeType(s.Type, true)
+	w.writeMethods(s.Type)
+	if options.IncludeDocs {
+		w.writeDocs(s)
+	}
+
+	// the named types referenced from the shape (w.named is growing while iterating)
+	for i := 0; i < len(w.named); i++ {
+		rt := w.named[i]
+		w.WriteString("\ntype ")
+		w.WriteString(rt.PkgPath())
+		w.WriteByte('.')
+		w.WriteString(rt.Name())
+		w.WriteByte(' ')
+		w.writeType(rt, true)
+		w.writeMethods(rt)
+		if options.IncludeDocs {
+			w.writeDocs(s.e.extract(rt, rzero(rt)))
+		}
+	}
+
+	sum := sha256.Sum256([]byte(w.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeMethods writes the method set of the named type (including the methods of the pointer receiver).
+func (w *typeWriter) writeMethods(rt reflect.Type) {
+	if rt.Name() == "" || rt.Kind() == reflect.Interface {
+		return
+	}
+	mt := reflect.PointerTo(rt)
+	for i, n := 0, mt.NumMethod(); i < n; i++ {
+		m := mt.Method(i)
+		w.WriteString("\n\tfunc ")
+		w.WriteString(m.Name)
+		w.writeSignature(m.Type, 1)
+	}
+}
+
+// writeDocs writes the docs of the shape. For struct, the docs of fields are also written, and for interface, the docs of methods are also written.
+func (w *typeWriter) writeDocs(s *Shape) {
+	switch {
+	case s.ID.pc != 0:
+		w.writeDoc("", s.Func().Doc())
+	case s.Kind == reflect.Struct:
+		st := s.Struct()
+		w.writeDoc("", st.Doc())
+		for _, f := range st.Fields() {
+			w.writeDoc(f.Name, f.Doc)
+		}
+	case s.Kind == reflect.Interface:
+		iface := s.Interface()
+		w.writeDoc("", iface.Doc())
+		for _, m := range iface.Methods() {
+			w.writeDoc(m.Name, m.Doc)
+		}
+	case s.Name != "" && s.Package != nil && s.Package.Path != "": // not builtin types
+		w.writeDoc("", s.Named().Doc())
+	}
+}
+
+func (w *typeWriter) writeDoc(name string, doc string) {
+	if doc == "" {
+		return
+	}
+	w.WriteString("\n\t// ")
+	if name != "" {
+		w.WriteString(name)
+		w.WriteString(": ")
+	}
+	w.WriteString(strconv.Quote(doc))
+}
@@ -0,0 +1,79 @@
+package reflectshape_test
+
+import (
+	"testing"
+
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+type Account struct {
+	Name string `json:"name"`
+}
+
+type AccountWithAnotherTag struct {
+	Name string `json:"nickname"`
+}
+
+type AccountWithPointer struct {
+	Name *string `json:"name"`
+}
+
+// AccountWithDoc is the documented account.
+type AccountWithDoc struct {
+	Name string `json:"name"` // name of account
+}
+
+type AccountWithMethod struct {
+	Name string `json:"name"`
+}
+
+func (a *AccountWithMethod) Greet(prefix string) string { return prefix + a.Name }
+
+type Node struct {
+	Value    int
+	Parent   *Node
+	Children []*Node
+	Owner    *Account
+}
+
+func TestFingerprint(t *testing.T) {
+	extract := func(ob any) *reflectshape.Shape {
+		return (&reflectshape.Config{IncludeGoTestFiles: true}).Extract(ob)
+	}
+
+	cases := []struct {
+		msg     string
+		x, y    any
+		options reflectshape.FingerprintOptions
+		same    bool
+	}{
+		{msg: "same", x: Account{}, y: &Account{}, same: true},
+		{msg: "tag", x: Account{}, y: AccountWithAnotherTag{}, same: false},
+		{msg: "pointer", x: Account{}, y: AccountWithPointer{}, same: false},
+		{msg: "pointer-ignored", x: Account{}, y: AccountWithPointer{}, options: reflectshape.FingerprintOptions{IgnorePointerLevel: true}, same: true},
+		{msg: "doc-ignored", x: Account{}, y: AccountWithDoc{}, same: true},
+		{msg: "doc", x: Account{}, y: AccountWithDoc{}, options: reflectshape.FingerprintOptions{IncludeDocs: true}, same: false},
+		{msg: "method", x: Account{}, y: AccountWithMethod{}, same: false},
+		{msg: "func", x: F0, y: F1, same: true},
+		{msg: "func-different-signature", x: F0, y: UseContext, same: false},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			x := extract(c.x).FingerprintWith(c.options)
+			y := extract(c.y).FingerprintWith(c.options)
+			if got := x == y; c.same != got {
+				t.Errorf("Shape.Fingerprint(): same=%v is expected, but %q, %q", c.same, x, y)
+			}
+		})
+	}
+
+	t.Run("recursive", func(t *testing.T) {
+		x := extract(Node{}).FingerprintWith(reflectshape.FingerprintOptions{IncludeDocs: true})
+		y := cfg.Extract(&Node{}).FingerprintWith(reflectshape.FingerprintOptions{IncludeDocs: true}) // another extractor
+		if x != y {
+			t.Errorf("Shape.Fingerprint(): must be same, but %q != %q", x, y)
+		}
+	})
+}
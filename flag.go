@@ -0,0 +1,149 @@
+package reflectshape
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type FlagOptions struct {
+	Tag    string // the tag name of flag names, default is "flag" (e.g. `flag:"port"`, `flag:"-"` is skipped)
+	Prefix string // the prefix of flag names
+}
+
+// BindFlags binds the fields of ptr (*T, the pointer of the struct shape's value) to the flag set.
+//
+// The flag names are the tag values or the kebab-case field names (e.g. "MaxConns" -> "max-conns"),
+// the fields of nested structs become prefixed flags (e.g. "db.max-conns"), and the fields of embedded structs are flattened.
+// The field docs are used as the usage, the current field values are used as the defaults, and Struct.Doc() is used as the command description.
+// The nil pointers of nested structs are allocated when their flags are set. If a flag name is already defined in fs (or duplicated), an error is returned and fs is not modified.
+func (s *Shape) BindFlags(fs *flag.FlagSet, ptr interface{}, options FlagOptions) error {
+	rv, err := s.structValue(ptr)
+	if err != nil {
+		return err
+	}
+	if options.Tag == "" {
+		options.Tag = "flag"
+	}
+
+	st := s.Struct()
+	var flags []*flagField
+	collectFlags(&flags, st, rv, nil, options, options.Prefix, map[reflect.Type]bool{s.Type: true})
+
+	// check all names before fs.Var() (it panics on duplication), so that fs is not modified on error
+	seen := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		if fs.Lookup(f.name) != nil || seen[f.name] {
+			return fmt.Errorf("field %s: flag %q is already defined", f.field, f.name)
+		}
+		seen[f.name] = true
+	}
+	for _, f := range flags {
+		fs.Var(f.value, f.name, f.usage)
+	}
+
+	if doc := st.Doc(); doc != "" {
+		fs.Usage = func() {
+			out := fs.Output()
+			fmt.Fprintf(out, "Usage of %s:\n\n", fs.Name())
+			fmt.Fprintln(out, strings.TrimSpace(doc))
+			fmt.Fprintln(out, "")
+			fs.PrintDefaults()
+		}
+	}
+	return nil
+}
+
+// flagField is the flag to be defined for the field.
+type flagField struct {
+	name  string
+	usage string
+	field string
+	value *flagValue
+}
+
+func collectFlags(flags *[]*flagField, s *Struct, root reflect.Value, index []int, options FlagOptions, prefix string, path map[reflect.Type]bool) {
+	for i, f := range s.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name, ok := f.Tag.Lookup(options.Tag)
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = kebabCase(f.Name)
+		}
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		// nested struct (if the field is pointer, it is allocated when the flag is set)
+		if f.Shape.Kind == reflect.Struct && f.Shape.Lv <= 1 && !canSetString(f.Type) {
+			if path[f.Shape.Type] {
+				continue // recursive
+			}
+			nestedPrefix := prefix + name + "."
+			if f.Anonymous {
+				nestedPrefix = prefix
+			}
+			path[f.Shape.Type] = true
+			collectFlags(flags, f.Shape.Struct(), root, fieldIndex, options, nestedPrefix, path)
+			delete(path, f.Shape.Type)
+			continue
+		}
+
+		if !canSetString(f.Type) {
+			continue
+		}
+		*flags = append(*flags, &flagField{
+			name:  prefix + name,
+			usage: strings.TrimSpace(f.Doc),
+			field: f.Name,
+			value: &flagValue{root: root, index: fieldIndex, rt: f.Type},
+		})
+	}
+}
+
+// flagValue is the flag.Value for the field.
+type flagValue struct {
+	root  reflect.Value // the struct value
+	index []int         // the field index sequence from the root, the pointers of nested structs are followed
+	rt    reflect.Type  // the type of the field
+}
+
+// field returns the field value. If alloc is false and the field is in the nil nested struct, returns the invalid value.
+func (v *flagValue) field(alloc bool) reflect.Value {
+	rv := v.root
+	for _, i := range v.index {
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(i)
+	}
+	return rv
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	rv := v.field(false)              // invalid for the zero flagValue (flag.PrintDefaults() calls String() of it)
+	if !rv.IsValid() || rv.IsZero() { // zero value is not shown as the default in the usage
+		return ""
+	}
+	return formatValue(rv)
+}
+
+func (v *flagValue) Set(s string) error {
+	return setString(v.field(true), s)
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.rt != nil && v.rt.Kind() == reflect.Bool
+}
@@ -0,0 +1,128 @@
+package reflectshape_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// ServeOptions is the options of serve command.
+// The serve command starts the HTTP server.
+type ServeOptions struct {
+	// Address to listen.
+	Addr    string `flag:"addr"`
+	Port    int    // port number
+	Verbose bool
+	Timeout time.Duration
+	Tags    []string
+	DB      *DBConfig
+	Log     LogConfig
+	Secret  string `flag:"-"`
+}
+
+type DuplicatedFlags struct {
+	Name     string
+	FullName string `flag:"name"`
+}
+
+func TestBindFlags(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		ob := &ServeOptions{Port: 8000}
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		if err := cfg.Extract(ob).BindFlags(fs, ob, reflectshape.FlagOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		args := []string{"-addr", "127.0.0.1", "-verbose", "-timeout", "3s", "-tags", "a,b", "-db.url", "mysql://", "-db.max-conns", "5", "-log.level", "debug"}
+		if err := fs.Parse(args); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := &ServeOptions{
+			Addr:    "127.0.0.1",
+			Port:    8000,
+			Verbose: true,
+			Timeout: 3 * time.Second,
+			Tags:    []string{"a", "b"},
+			DB:      &DBConfig{URL: "mysql://", MaxConns: 5},
+			Log:     LogConfig{Level: "debug"},
+		}
+		if diff := cmp.Diff(want, ob); diff != "" {
+			t.Errorf("BindFlags(): -want, +got: \n%v", diff)
+		}
+		if f := fs.Lookup("secret"); f != nil {
+			t.Errorf("the field tagged with `flag:\"-\"` must be skipped, but found %v", f.Name)
+		}
+	})
+
+	t.Run("not-allocated", func(t *testing.T) {
+		ob := &ServeOptions{}
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		if err := cfg.Extract(ob).BindFlags(fs, ob, reflectshape.FlagOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if ob.DB != nil {
+			t.Errorf("BindFlags(): the nested struct must not be allocated until the flag is set, but %+v", ob.DB)
+		}
+		if err := fs.Parse([]string{"-port", "8080"}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if ob.DB != nil {
+			t.Errorf("Parse(): the nested struct must not be allocated, the flags are not set, but %+v", ob.DB)
+		}
+	})
+
+	t.Run("duplicated", func(t *testing.T) {
+		ob := &ServeOptions{}
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		fs.String("port", "", "already defined")
+		err := cfg.Extract(ob).BindFlags(fs, ob, reflectshape.FlagOptions{})
+		if err == nil || !strings.Contains(err.Error(), `"port"`) {
+			t.Errorf("BindFlags(): the error of duplicated flag is expected, but %v", err)
+		}
+		if f := fs.Lookup("addr"); f != nil { // not modified
+			t.Errorf("BindFlags(): the flag set must not be modified on error, but %q is defined", f.Name)
+		}
+	})
+
+	t.Run("duplicated-in-struct", func(t *testing.T) {
+		ob := &DuplicatedFlags{}
+		fs := flag.NewFlagSet("dup", flag.ContinueOnError)
+		err := cfg.Extract(ob).BindFlags(fs, ob, reflectshape.FlagOptions{})
+		if err == nil || !strings.Contains(err.Error(), `"name"`) {
+			t.Errorf("BindFlags(): the error of duplicated flag is expected, but %v", err)
+		}
+		if f := fs.Lookup("name"); f != nil { // not modified
+			t.Errorf("BindFlags(): the flag set must not be modified on error, but %q is defined", f.Name)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		ob := &ServeOptions{Port: 8000}
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		if err := cfg.Extract(ob).BindFlags(fs, ob, reflectshape.FlagOptions{Prefix: "serve."}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		var buf bytes.Buffer
+		fs.SetOutput(&buf)
+		fs.Usage()
+		got := buf.String()
+
+		for _, want := range []string{
+			"ServeOptions is the options of serve command.\nThe serve command starts the HTTP server.\n",
+			"-serve.addr value\n    \tAddress to listen.\n",
+			"-serve.port value\n    \tport number (default 8000)\n",
+			"-serve.verbose\n",
+			"-serve.db.max-conns value\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("usage: %q is not contained in\n%s", want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package reflectshape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StableID returns the identifier that is stable across processes, builds and machines.
+// The format is "<canonical full name>#<hash of the signature>" (e.g. "github.com/foo/bar.Person#3f1a0c9e2b7d4a65").
+// Unlike ID, the pointer level is not included, and it is the same while the name and the signature are the same.
+func (s *Shape) StableID() string {
+	w := &typeWriter{}
+	w.writeType(s.Type, true)
+	sum := sha256.Sum256([]byte(w.String()))
+	return s.canonicalName() + "#" + hex.EncodeToString(sum[:8])
+}
+
+// canonicalName returns the name qualified by the package path. For unnamed types, it is the canonical type string.
+func (s *Shape) canonicalName() string {
+	if s.Name == "" {
+		w := &typeWriter{}
+		w.writeType(s.Type, false)
+		return w.String()
+	}
+	if s.Package == nil || s.Package.Path == "" {
+		return s.Name
+	}
+	return s.Package.Path + "." + s.Name
+}
+
+// typeWriter writes the canonical type string, the named types are qualified by the package path (not the package name).
+type typeWriter struct {
+	strings.Builder
+
+	ignorePointer bool           // if true, "*" is not written
+	named         []reflect.Type // the referenced named types, in the order of appearance
+	seen          map[reflect.Type]bool
+}
+
+// writeType writes the type. If expand is true, the underlying type of the top-level named type is written.
+func (w *typeWriter) writeType(rt reflect.Type, expand bool) {
+	if rt.Name() != "" && !expand {
+		if pkgPath := rt.PkgPath(); pkgPath != "" {
+			w.WriteString(pkgPath)
+			w.WriteByte('.')
+			if w.seen != nil && !w.seen[rt] {
+				w.seen[rt] = true
+				w.named = append(w.named, rt)
+			}
+		}
+		w.WriteString(rt.Name())
+		return
+	}
+
+	switch rt.Kind() {
+	case reflect.Pointer:
+		if !w.ignorePointer {
+			w.WriteByte('*')
+		}
+		w.writeType(rt.Elem(), false)
+	case reflect.Slice:
+		w.WriteString("[]")
+		w.writeType(rt.Elem(), false)
+	case reflect.Array:
+		w.WriteByte('[')
+		w.WriteString(strconv.Itoa(rt.Len()))
+		w.WriteByte(']')
+		w.writeType(rt.Elem(), false)
+	case reflect.Map:
+		w.WriteString("map[")
+		w.writeType(rt.Key(), false)
+		w.WriteByte(']')
+		w.writeType(rt.Elem(), false)
+	case reflect.Chan:
+		switch rt.ChanDir() {
+		case reflect.RecvDir:
+			w.WriteString("<-chan ")
+		case reflect.SendDir:
+			w.WriteString("chan<- ")
+		default:
+			w.WriteString("chan ")
+		}
+		w.writeType(rt.Elem(), false)
+	case reflect.Func:
+		w.WriteString("func")
+		w.writeSignature(rt, 0)
+	case reflect.Struct:
+		w.WriteString("struct {")
+		for i, n := 0, rt.NumField(); i < n; i++ {
+			f := rt.Field(i)
+			if i > 0 {
+				w.WriteByte(';')
+			}
+			w.WriteByte(' ')
+			if !f.Anonymous {
+				w.WriteString(f.Name)
+				w.WriteByte(' ')
+			}
+			w.writeType(f.Type, false)
+			if f.Tag != "" {
+				w.WriteByte(' ')
+				w.WriteString(strconv.Quote(string(f.Tag)))
+			}
+		}
+		w.WriteString(" }")
+	case reflect.Interface:
+		w.WriteString("interface {")
+		for i, n := 0, rt.NumMethod(); i < n; i++ {
+			m := rt.Method(i)
+			if i > 0 {
+				w.WriteByte(';')
+			}
+			w.WriteByte(' ')
+			w.WriteString(m.Name)
+			w.writeSignature(m.Type, 0)
+		}
+		w.WriteString(" }")
+	default:
+		w.WriteString(rt.Kind().String())
+	}
+}
+
+// writeSignature writes the signature of the func type, the first offset arguments are skipped (e.g. the receiver of the method).
+func (w *typeWriter) writeSignature(rt reflect.Type, offset int) {
+	w.WriteByte('(')
+	for i, n := offset, rt.NumIn(); i < n; i++ {
+		if i > offset {
+			w.WriteString(", ")
+		}
+		if rt.IsVariadic() && i == n-1 {
+			w.WriteString("...")
+			w.writeType(rt.In(i).Elem(), false)
+			continue
+		}
+		w.writeType(rt.In(i), false)
+	}
+	w.WriteByte(')')
+
+	switch n := rt.NumOut(); n {
+	case 0:
+	case 1:
+		w.WriteByte(' ')
+		w.writeType(rt.Out(0), false)
+	default:
+		w.WriteString(" (")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.writeType(rt.Out(i), false)
+		}
+		w.WriteByte(')')
+	}
+}
@@ -0,0 +1,159 @@
+// Package symbol parses the symbol names of runtime functions (runtime.Func.Name()).
+package symbol
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Symbol is the parsed symbol name.
+//
+//	gopkg.in/yaml%2ev3.(*Decoder).Decode-fm
+//	-> {PkgPath: "gopkg.in/yaml.v3", Recv: "Decoder", IsPointer: true, Name: "Decode", IsMethodValue: true}
+//	github.com/foo/bar.Map[...].func1.2
+//	-> {PkgPath: "github.com/foo/bar", Name: "Map", TypeArgs: "...", Closures: ["func1", "2"]}
+type Symbol struct {
+	Raw string
+
+	PkgPath   string
+	Recv      string // receiver type name, without "(*)" and type arguments
+	IsPointer bool   // if true, the receiver is pointer (e.g. (*T).M)
+	Name      string // function name or method name
+	TypeArgs  string // type arguments in brackets of the receiver or the function (runtime elides them as "...")
+	Closures  []string
+
+	IsMethodValue bool // if true, the symbol is the wrapper of method value (e.g. T.M-fm)
+}
+
+// IsMethod reports whether the symbol is method (or the closure in the method).
+func (s Symbol) IsMethod() bool {
+	return s.Recv != ""
+}
+
+// IsClosure reports whether the symbol is anonymous function (including defer/go wrappers).
+func (s Symbol) IsClosure() bool {
+	return len(s.Closures) > 0
+}
+
+// Qualified returns the name without package path (e.g. "T.M", "F.func1").
+func (s Symbol) Qualified() string {
+	parts := make([]string, 0, 2+len(s.Closures))
+	if s.Recv != "" {
+		parts = append(parts, s.Recv)
+	}
+	parts = append(parts, s.Name)
+	parts = append(parts, s.Closures...)
+	return strings.Join(parts, ".")
+}
+
+var closureRegex = regexp.MustCompile(`^(func|deferwrap|gowrap)?\d+$`)
+
+// Parse parses the symbol name.
+func Parse(name string) Symbol {
+	s := Symbol{Raw: name}
+	if strings.HasSuffix(name, "-fm") {
+		s.IsMethodValue = true
+		name = strings.TrimSuffix(name, "-fm")
+	}
+
+	// the package path is terminated by the first dot after the last slash (the dots in the last element are escaped as %2e)
+	lastSlash := -1
+	depth := 0
+	for i, c := range name {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				lastSlash = i
+			}
+		}
+	}
+	rest := name
+	if i := strings.IndexByte(name[lastSlash+1:], '.'); i >= 0 {
+		s.PkgPath = unescape(name[:lastSlash+1+i])
+		rest = name[lastSlash+1+i+1:]
+	}
+
+	// closures in the package-level variable initializers (e.g. "main.glob..func1")
+	if strings.HasPrefix(rest, "glob..") {
+		s.Name = "glob."
+		s.Closures = splitTopLevel(rest[len("glob.."):])
+		return s
+	}
+
+	parts := splitTopLevel(rest)
+	if len(parts) == 0 {
+		return s
+	}
+
+	// receiver
+	switch first := parts[0]; {
+	case strings.HasPrefix(first, "(*") && strings.HasSuffix(first, ")"):
+		s.IsPointer = true
+		s.Recv, s.TypeArgs = cutTypeArgs(first[2 : len(first)-1])
+		parts = parts[1:]
+	case len(parts) > 1 && !closureRegex.MatchString(parts[1]):
+		s.Recv, s.TypeArgs = cutTypeArgs(first)
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return s
+	}
+
+	// function
+	name, typeArgs := cutTypeArgs(parts[0])
+	s.Name = name
+	if typeArgs != "" {
+		s.TypeArgs = typeArgs
+	}
+	if len(parts) > 1 {
+		s.Closures = parts[1:]
+	}
+	return s
+}
+
+// splitTopLevel splits the name by dots, except the dots in brackets.
+func splitTopLevel(name string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i, c := range name {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, name[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(name) {
+		parts = append(parts, name[start:])
+	}
+	return parts
+}
+
+// cutTypeArgs cuts the type arguments (e.g. "List[...]" -> "List", "...").
+func cutTypeArgs(name string) (string, string) {
+	i := strings.IndexByte(name, '[')
+	if i < 0 || !strings.HasSuffix(name, "]") {
+		return name, ""
+	}
+	return name[:i], name[i+1 : len(name)-1]
+}
+
+// unescape unescapes the package path (e.g. "gopkg.in/yaml%2ev3" -> "gopkg.in/yaml.v3").
+func unescape(path string) string {
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return path
+	}
+	return unescaped
+}
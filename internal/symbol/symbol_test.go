@@ -0,0 +1,76 @@
+package symbol
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input string
+		want  Symbol
+	}{
+		{input: "github.com/podhmo/reflect-shape_test.F1",
+			want: Symbol{PkgPath: "github.com/podhmo/reflect-shape_test", Name: "F1"}},
+		{input: "main.main",
+			want: Symbol{PkgPath: "main", Name: "main"}},
+		{input: "net/http.ListenAndServe",
+			want: Symbol{PkgPath: "net/http", Name: "ListenAndServe"}},
+		// method
+		{input: "github.com/podhmo/reflect-shape_test.S0.M",
+			want: Symbol{PkgPath: "github.com/podhmo/reflect-shape_test", Recv: "S0", Name: "M"}},
+		{input: "github.com/podhmo/reflect-shape_test.(*S1).M",
+			want: Symbol{PkgPath: "github.com/podhmo/reflect-shape_test", Recv: "S1", IsPointer: true, Name: "M"}},
+		// method value
+		{input: "github.com/podhmo/reflect-shape_test.S0.M-fm",
+			want: Symbol{PkgPath: "github.com/podhmo/reflect-shape_test", Recv: "S0", Name: "M", IsMethodValue: true}},
+		{input: "github.com/podhmo/reflect-shape_test.(*S1).M-fm",
+			want: Symbol{PkgPath: "github.com/podhmo/reflect-shape_test", Recv: "S1", IsPointer: true, Name: "M", IsMethodValue: true}},
+		// dotted import path
+		{input: "gopkg.in/yaml%2ev3.Unmarshal",
+			want: Symbol{PkgPath: "gopkg.in/yaml.v3", Name: "Unmarshal"}},
+		{input: "gopkg.in/yaml%2ev3.(*Decoder).Decode",
+			want: Symbol{PkgPath: "gopkg.in/yaml.v3", Recv: "Decoder", IsPointer: true, Name: "Decode"}},
+		{input: "example.com/gen/sub%2ev2.F.func1",
+			want: Symbol{PkgPath: "example.com/gen/sub.v2", Name: "F", Closures: []string{"func1"}}},
+		{input: "example.com/x%2541.F",
+			want: Symbol{PkgPath: "example.com/x%41", Name: "F"}},
+		// package-level variable initializers
+		{input: "main.glob..func1",
+			want: Symbol{PkgPath: "main", Name: "glob.", Closures: []string{"func1"}}},
+		// generics
+		{input: "main.Map[...]",
+			want: Symbol{PkgPath: "main", Name: "Map", TypeArgs: "..."}},
+		{input: "main.(*List[...]).Push",
+			want: Symbol{PkgPath: "main", Recv: "List", IsPointer: true, Name: "Push", TypeArgs: "..."}},
+		{input: "main.List[...].Len-fm",
+			want: Symbol{PkgPath: "main", Recv: "List", Name: "Len", TypeArgs: "...", IsMethodValue: true}},
+		{input: "main.Map[go.shape.int,github.com/foo/bar.T]",
+			want: Symbol{PkgPath: "main", Name: "Map", TypeArgs: "go.shape.int,github.com/foo/bar.T"}},
+		// closure
+		{input: "main.main.func1",
+			want: Symbol{PkgPath: "main", Name: "main", Closures: []string{"func1"}}},
+		{input: "main.main.func1.2",
+			want: Symbol{PkgPath: "main", Name: "main", Closures: []string{"func1", "2"}}},
+		{input: "main.init.func1",
+			want: Symbol{PkgPath: "main", Name: "init", Closures: []string{"func1"}}},
+		{input: "github.com/foo/bar.(*T).M.func1",
+			want: Symbol{PkgPath: "github.com/foo/bar", Recv: "T", IsPointer: true, Name: "M", Closures: []string{"func1"}}},
+		{input: "github.com/foo/bar.F.deferwrap1",
+			want: Symbol{PkgPath: "github.com/foo/bar", Name: "F", Closures: []string{"deferwrap1"}}},
+		{input: "github.com/foo/bar.F.gowrap2",
+			want: Symbol{PkgPath: "github.com/foo/bar", Name: "F", Closures: []string{"gowrap2"}}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.input, func(t *testing.T) {
+			c.want.Raw = c.input
+			got := Parse(c.input)
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,25 +4,75 @@ import (
 	"reflect"
 )
 
+type IsZeroOptions struct {
+	EmptyAsZero     bool   // if true, the empty (but not nil) slices and maps are treated as zero
+	FollowInterface bool   // if true, the dynamic value of the interface is checked recursively, instead of checking nil
+	SkipTag         string // if set, the fields with the tag value "-" are skipped (e.g. "json" skips the fields tagged with `json:"-"`)
+}
+
+// zeroer is the interface for the types having the custom zero check (e.g. time.Time).
+type zeroer interface {
+	IsZero() bool
+}
+
+var rzeroerType = reflect.TypeOf((*zeroer)(nil)).Elem()
+
+// IsZeroRecursive reports whether the value is zero. For struct, it is zero if all fields are zero recursively.
 func IsZeroRecursive(rt reflect.Type, rv reflect.Value) bool {
+	return IsZeroRecursiveWith(rt, rv, IsZeroOptions{})
+}
+
+// IsZeroRecursiveWith is the version of IsZeroRecursive() with options.
+// If the type has IsZero() bool method, the method is used.
+func IsZeroRecursiveWith(rt reflect.Type, rv reflect.Value, options IsZeroOptions) bool {
+	if rt == nil || !rv.IsValid() {
+		return true
+	}
+
+	if rv.CanInterface() {
+		var z zeroer
+		switch {
+		case rt.Implements(rzeroerType):
+			if (rt.Kind() == reflect.Pointer || rt.Kind() == reflect.Interface) && rv.IsNil() {
+				return true
+			}
+			z = rv.Interface().(zeroer)
+		case reflect.PointerTo(rt).Implements(rzeroerType): // pointer receiver
+			if !rv.CanAddr() {
+				copied := reflect.New(rt).Elem()
+				copied.Set(rv)
+				rv = copied
+			}
+			z = rv.Addr().Interface().(zeroer)
+		}
+		if z != nil {
+			if zero, ok := callIsZero(z); ok {
+				return zero
+			}
+			// if IsZero() panics, fallback to the check by the structure
+		}
+	}
+
 	switch rt.Kind() {
-	case reflect.Bool:
-		return rv.IsZero()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return rv.IsZero()
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return rv.IsZero()
-	case reflect.Float32, reflect.Float64:
-		return rv.IsZero()
-	case reflect.Complex64, reflect.Complex128:
-		return rv.IsZero()
-	case reflect.String:
+	case reflect.Bool, reflect.String, reflect.Uintptr, reflect.UnsafePointer,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 		return rv.IsZero()
 	case reflect.Struct:
 		for i := 0; i < rt.NumField(); i++ {
 			ft := rt.Field(i)
-			fv := rv.Field(i)
-			if !IsZeroRecursive(ft.Type, fv) {
+			if options.SkipTag != "" && isSkipped(ft, options.SkipTag) {
+				continue
+			}
+			if !IsZeroRecursiveWith(ft.Type, rv.Field(i), options) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < rt.Len(); i++ {
+			if !IsZeroRecursiveWith(rt.Elem(), rv.Index(i), options) {
 				return false
 			}
 		}
@@ -31,10 +81,50 @@ func IsZeroRecursive(rt reflect.Type, rv reflect.Value) bool {
 		if rv.IsNil() {
 			return true
 		}
-		return IsZeroRecursive(rt.Elem(), rv.Elem())
-	// case reflect.Invalid:
-	// case reflect.Uintptr, reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
-	default:
-		return !rv.IsValid() || rv.IsNil()
+		return IsZeroRecursiveWith(rt.Elem(), rv.Elem(), options)
+	case reflect.Interface:
+		if rv.IsNil() {
+			return true
+		}
+		if !options.FollowInterface {
+			return false
+		}
+		elem := rv.Elem()
+		return IsZeroRecursiveWith(elem.Type(), elem, options)
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return true
+		}
+		return options.EmptyAsZero && rv.Len() == 0
+	case reflect.Chan, reflect.Func:
+		return rv.IsNil()
+	default: // reflect.Invalid
+		return true
 	}
 }
+
+// callIsZero calls the IsZero() method, ok is false if the method panics (e.g. the zero reflect.Value).
+func callIsZero(z zeroer) (zero bool, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			zero, ok = false, false
+		}
+	}()
+	return z.IsZero(), true
+}
+
+// IsZeroValue reports whether the value of the shape is zero (see IsZeroRecursiveWith()), the fields can be skipped by tag with IsZeroOptions.SkipTag.
+// The value can be T or *T (the pointer of the shape's type), the nil pointer is zero.
+func (s *Shape) IsZeroValue(rv reflect.Value, options IsZeroOptions) bool {
+	for rv.IsValid() && rv.Kind() == reflect.Pointer && rv.Type() != s.Type {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	return IsZeroRecursiveWith(s.Type, rv, options)
+}
+
+func isSkipped(f reflect.StructField, tag string) bool {
+	return f.Tag.Get(tag) == "-" // `json:"-,"` is the field named "-"
+}
@@ -3,6 +3,7 @@ package reflectshape_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	reflectshape "github.com/podhmo/reflect-shape"
 )
@@ -41,6 +42,26 @@ func TestIsZeroRecursive(t *testing.T) {
 		{"zero-rec-struct", W{}, true},
 		{"zero-rec-struct2", W{S: S{}}, true},
 		{"not-zero-rec-struct", W{S: S{Age: 20}}, false},
+		// array
+		{"zero-array", [2]int{}, true},
+		{"not-zero-array", [2]int{0, 1}, false},
+		{"zero-struct-array", [1]S{}, true},
+		// chan, func
+		{"nil-chan", func() chan int { return nil }(), true},
+		{"chan", make(chan int), false},
+		{"nil-func", func() func() { return nil }(), true},
+		// interface
+		{"nil-interface-field", struct{ V any }{}, true},
+		{"interface-field", struct{ V any }{V: 0}, false},
+		// custom IsZero() bool
+		{"zero-time", time.Time{}, true},
+		{"time", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"zero-time-field", struct{ CreatedAt time.Time }{}, true},
+		{"custom-zero", customZero{Value: "<zero>"}, true},
+		{"custom-zero-field-pointer-receiver", struct{ V pointerCustomZero }{V: pointerCustomZero{Value: "<zero>"}}, true},
+		// the IsZero() of the zero reflect.Value panics
+		{"zero-reflect-value-field", struct{ V reflect.Value }{}, true},
+		{"reflect-value-field", struct{ V reflect.Value }{V: reflect.ValueOf(1)}, false},
 	}
 	for _, tt := range tests {
 		rt := reflect.TypeOf(tt.v)
@@ -52,3 +73,80 @@ func TestIsZeroRecursive(t *testing.T) {
 		})
 	}
 }
+
+type customZero struct{ Value string }
+
+func (v customZero) IsZero() bool { return v.Value == "" || v.Value == "<zero>" }
+
+type pointerCustomZero struct{ Value string }
+
+func (v *pointerCustomZero) IsZero() bool { return v.Value == "" || v.Value == "<zero>" }
+
+func TestIsZeroRecursiveWith(t *testing.T) {
+	type S struct {
+		Name  string
+		Cache string `json:"-"`
+		Extra any
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		options reflectshape.IsZeroOptions
+		want    bool
+	}{
+		{"empty-slice", []int{}, reflectshape.IsZeroOptions{}, false},
+		{"empty-slice-as-zero", []int{}, reflectshape.IsZeroOptions{EmptyAsZero: true}, true},
+		{"slice-as-zero", []int{0}, reflectshape.IsZeroOptions{EmptyAsZero: true}, false},
+		{"empty-map-as-zero", map[string]int{}, reflectshape.IsZeroOptions{EmptyAsZero: true}, true},
+		{"interface", S{Extra: S{}}, reflectshape.IsZeroOptions{}, false},
+		{"interface-followed", S{Extra: S{}}, reflectshape.IsZeroOptions{FollowInterface: true}, true},
+		{"interface-followed-not-zero", S{Extra: &S{Name: "foo"}}, reflectshape.IsZeroOptions{FollowInterface: true}, false},
+		{"tagged", S{Cache: "x"}, reflectshape.IsZeroOptions{}, false},
+		{"tagged-skipped", S{Cache: "x"}, reflectshape.IsZeroOptions{SkipTag: "json"}, true},
+		{"tagged-skipped-nested", &ZeroCheckTarget{Parent: &ZeroCheckTarget{UpdatedAt: time.Now()}}, reflectshape.IsZeroOptions{SkipTag: "zero"}, true},
+		{"not-zero-nested", &ZeroCheckTarget{Parent: &ZeroCheckTarget{Name: "foo"}}, reflectshape.IsZeroOptions{SkipTag: "zero"}, false},
+	}
+	for _, tt := range tests {
+		rt := reflect.TypeOf(tt.v)
+		rv := reflect.ValueOf(tt.v)
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reflectshape.IsZeroRecursiveWith(rt, rv, tt.options); got != tt.want {
+				t.Errorf("IsZeroRecursiveWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type ZeroCheckTarget struct {
+	Name      string
+	UpdatedAt time.Time `zero:"-"`
+	Parent    *ZeroCheckTarget
+}
+
+func TestShapeIsZeroValue(t *testing.T) {
+	shape := cfg.Extract(&ZeroCheckTarget{})
+	options := reflectshape.IsZeroOptions{SkipTag: "zero"}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want bool
+	}{
+		{"zero", ZeroCheckTarget{}, true},
+		{"zero-pointer", &ZeroCheckTarget{}, true},
+		{"nil-pointer", (*ZeroCheckTarget)(nil), true},
+		{"skipped", &ZeroCheckTarget{UpdatedAt: time.Now()}, true},
+		{"not-zero", &ZeroCheckTarget{Name: "foo"}, false},
+		{"not-zero-nested", &ZeroCheckTarget{Parent: &ZeroCheckTarget{Name: "foo"}}, false},
+		{"zero-nested", &ZeroCheckTarget{Parent: &ZeroCheckTarget{UpdatedAt: time.Now()}}, true},
+	}
+	for _, tt := range tests {
+		rv := reflect.ValueOf(tt.v)
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shape.IsZeroValue(rv, options); got != tt.want {
+				t.Errorf("Shape.IsZeroValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
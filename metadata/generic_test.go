@@ -0,0 +1,7 @@
+package metadata
+
+// Bag is the generic type declared in the other file than the function Take (see lookup_test.go).
+type Bag[T any] struct{}
+
+// Take takes the value
+func (b *Bag[T]) Take() (value T) { return }
@@ -5,6 +5,8 @@ import (
 	"runtime"
 	"strings"
 	"unsafe"
+
+	"github.com/podhmo/reflect-shape/internal/symbol"
 )
 
 type Accessor struct {
@@ -18,7 +20,7 @@ func New() *Accessor {
 
 func (a *Accessor) FuncForPC(pc uintptr) *runtime.Func {
 	rfunc := runtime.FuncForPC(pc)
-	if !strings.HasSuffix(rfunc.Name(), "-fm") {
+	if !symbol.Parse(rfunc.Name()).IsMethodValue {
 		return rfunc
 	}
 	target := strings.TrimSuffix(rfunc.Name(), "-fm")
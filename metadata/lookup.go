@@ -7,14 +7,18 @@ import (
 	"go/token"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/podhmo/commentof"
 	"github.com/podhmo/commentof/collect"
+	"github.com/podhmo/reflect-shape/internal/symbol"
 	"github.com/podhmo/reflect-shape/metadata/internal/unsaferuntime"
 	"golang.org/x/tools/go/packages"
 )
@@ -40,7 +44,10 @@ type Lookup struct {
 	IncludeGoTestFiles bool
 	IncludeUnexported  bool
 
-	cache map[string]*packageRef // TODO: lock
+	cache    map[string]*packageRef // TODO: lock
+	files    map[*token.File]*ast.File
+	comments map[*token.File]*commentIndex
+	pkgs     map[string]*Package
 }
 
 func NewLookup(fset *token.FileSet) *Lookup {
@@ -50,6 +57,9 @@ func NewLookup(fset *token.FileSet) *Lookup {
 		IncludeGoTestFiles: false,
 		IncludeUnexported:  false,
 		cache:              map[string]*packageRef{},
+		files:              map[*token.File]*ast.File{},
+		comments:           map[*token.File]*commentIndex{},
+		pkgs:               map[string]*Package{},
 	}
 }
 
@@ -57,6 +67,8 @@ type Func struct {
 	pc   uintptr
 	Raw  *collect.Func
 	Recv string
+
+	receiver Var
 }
 
 func (m *Func) Fullname() string {
@@ -71,9 +83,27 @@ func (m *Func) Doc() string {
 	return strings.TrimSpace(m.Raw.Doc)
 }
 
+func (m *Func) Pos() token.Pos {
+	return m.Raw.Pos
+}
+
+// Receiver returns the receiver of the method (e.g. func (s *S) M() -> {Name: "s"}).
+func (m *Func) Receiver() Var {
+	return m.receiver
+}
+
+// IsPointerReceiver reports whether the method's receiver is pointer (e.g. func (s *S) M()).
+func (m *Func) IsPointerReceiver() bool {
+	return strings.HasPrefix(m.Raw.Recv, "*")
+}
+
 type Var struct {
 	Name string
-	Doc  string
+	Doc  string // LeadingDoc or LineComment
+	Pos  token.Pos
+
+	LeadingDoc  string
+	LineComment string
 }
 
 func (m *Func) Args() []Var {
@@ -84,7 +114,7 @@ func (m *Func) Args() []Var {
 		if doc == "" {
 			doc = p.Comment
 		}
-		vars[i] = Var{Name: m.Raw.Params[id].Name, Doc: strings.TrimSpace(doc)}
+		vars[i] = Var{Name: p.Name, Doc: strings.TrimSpace(doc), Pos: p.Pos, LeadingDoc: strings.TrimSpace(p.Doc), LineComment: strings.TrimSpace(p.Comment)}
 	}
 	return vars
 }
@@ -97,7 +127,7 @@ func (m *Func) Returns() []Var {
 		if doc == "" {
 			doc = p.Comment
 		}
-		vars[i] = Var{Name: m.Raw.Returns[id].Name, Doc: strings.TrimSpace(doc)}
+		vars[i] = Var{Name: p.Name, Doc: strings.TrimSpace(doc), Pos: p.Pos, LeadingDoc: strings.TrimSpace(p.Doc), LineComment: strings.TrimSpace(p.Comment)}
 	}
 	return vars
 }
@@ -108,6 +138,41 @@ func (l *Lookup) LookupFromFunc(fn interface{}) (*Func, error) {
 }
 
 func (l *Lookup) LookupFromFuncForPC(pc uintptr) (*Func, error) {
+	m, err := l.lookupFromFuncForPC(pc)
+	if err != nil {
+		return nil, err
+	}
+	if m.Recv != "" {
+		m.receiver = l.receiver(m.Raw.Pos)
+	}
+	return m, nil
+}
+
+// receiver returns the receiver of the method declared at pos.
+func (l *Lookup) receiver(pos token.Pos) Var {
+	tf := l.Fset.File(pos)
+	if tf == nil {
+		return Var{}
+	}
+	f, ok := l.files[tf]
+	if !ok {
+		return Var{}
+	}
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Pos() != pos || decl.Recv == nil || len(decl.Recv.List) == 0 {
+			continue
+		}
+		field := decl.Recv.List[0]
+		if len(field.Names) == 0 {
+			return Var{Pos: field.Pos()}
+		}
+		return Var{Name: field.Names[0].Name, Pos: field.Pos()}
+	}
+	return Var{}
+}
+
+func (l *Lookup) lookupFromFuncForPC(pc uintptr) (*Func, error) {
 	rfunc := l.accessor.FuncForPC(pc)
 	if rfunc == nil {
 		return nil, fmt.Errorf("cannot find runtime.Func")
@@ -115,28 +180,18 @@ func (l *Lookup) LookupFromFuncForPC(pc uintptr) (*Func, error) {
 
 	filename, _ := rfunc.FileLine(rfunc.Entry())
 
-	// /<pkg name>.<function name>
-	// /<pkg name>.<recv>.<method name>
-	// /<pkg name>.<recv>.<method name>-fm
-
-	parts := strings.Split(rfunc.Name(), "/")
-	last := parts[len(parts)-1]
-	pkgname, name, isFunc := strings.Cut(last, ".")
-	_ = pkgname
-	if !isFunc {
+	// <pkg path>.<function name>
+	// <pkg path>.<recv>.<method name>
+	// <pkg path>.(*<recv>).<method name>
+	sym := symbol.Parse(rfunc.Name())
+	if sym.Name == "" {
 		return nil, fmt.Errorf("unexpected func: %v", rfunc.Name())
 	}
-
-	recv, name, isMethod := strings.Cut(name, ".")
-	if isMethod {
-		recv = strings.Trim(recv, "(*)")
-	} else {
-		name = recv
-		recv = ""
+	if sym.IsClosure() {
+		return nil, fmt.Errorf("lookup metadata of anonymous function %s, %w", rfunc.Name(), ErrNotSupported)
 	}
-	// log.Printf("pkgname:%-15s\trecv:%-10s\tname:%s\tisMethod:%v\n", pkgname, recv, name, isMethod)
-
-	pkgpath := rfuncPkgpath(rfunc)
+	recv, name, isMethod := sym.Recv, sym.Name, sym.IsMethod()
+	pkgpath := sym.PkgPath
 	p0, ok := l.cache[pkgpath]
 	if ok {
 		if p0.fullset {
@@ -169,7 +224,7 @@ func (l *Lookup) LookupFromFuncForPC(pc uintptr) (*Func, error) {
 				if DEBUG {
 					log.Println("\tOK func cache (full)", rfunc.Name())
 				}
-				return &Func{Raw: result}, nil
+				return &Func{pc: pc, Raw: result}, nil
 			}
 		}
 
@@ -216,12 +271,29 @@ func (l *Lookup) LookupFromFuncForPC(pc uintptr) (*Func, error) {
 		l.cache[pkgpath] = &packageRef{fullset: false, err: err} // error cache
 		return nil, err
 	}
-
+	l.files[l.Fset.File(f.Pos())] = f
+
+	// the functions of the earlier files may be overwritten by the methods of generic types in this file (collected as functions)
+	var functions map[string]*collect.Func
+	if p0 != nil {
+		functions = make(map[string]*collect.Func, len(p0.Functions))
+		for name, fn := range p0.Functions {
+			functions[name] = fn
+		}
+	}
 	p, err := commentof.File(l.Fset, f, commentof.WithIncludeUnexported(l.IncludeUnexported), func(b *collect.PackageBuilder) {
 		if p0 != nil {
 			b.Package = p0.Package // merge
 		}
 	})
+	if err == nil {
+		err = l.collectGenericMethods(p, []*ast.File{f})
+	}
+	if p != nil {
+		for name, fn := range functions { // merge without overwriting
+			p.Functions[name] = fn
+		}
+	}
 	if !ok && p != nil {
 		l.cache[pkgpath] = &packageRef{fullset: false, Package: p}
 	}
@@ -255,19 +327,16 @@ func (l *Lookup) LookupFromFuncForPC(pc uintptr) (*Func, error) {
 	}
 }
 
-func rfuncPkgpath(rfunc *runtime.Func) string {
-	parts := strings.Split(rfunc.Name(), ".")
-	return strings.Join(parts[:len(parts)-1], ".")
-}
-
 type Type struct {
-	Raw *collect.Object
+	Raw     *collect.Object
+	PkgPath string // the package path used for lookup
 }
 
 func (s *Type) Name() string {
 	return s.Raw.Name
 }
 
+// Doc returns the leading doc, or the line comment if the leading doc is empty.
 func (s *Type) Doc() string {
 	doc := s.Raw.Doc
 	if doc == "" {
@@ -276,6 +345,18 @@ func (s *Type) Doc() string {
 	return strings.TrimSpace(doc)
 }
 
+func (s *Type) LeadingDoc() string {
+	return strings.TrimSpace(s.Raw.Doc)
+}
+
+func (s *Type) LineComment() string {
+	return strings.TrimSpace(s.Raw.Comment)
+}
+
+func (s *Type) Pos() token.Pos {
+	return s.Raw.Pos
+}
+
 func (s *Type) FieldComments() map[string]string {
 	comments := make(map[string]string, len(s.Raw.Fields))
 	for _, f := range s.Raw.Fields {
@@ -288,14 +369,54 @@ func (s *Type) FieldComments() map[string]string {
 	return comments
 }
 
+// Field returns the metadata of the field (or the method of interface).
+func (s *Type) Field(name string) (Var, bool) {
+	f, ok := s.Raw.Fields[name]
+	if !ok {
+		return Var{}, false
+	}
+	doc := f.Doc
+	if doc == "" {
+		doc = f.Comment
+	}
+	return Var{Name: f.Name, Doc: strings.TrimSpace(doc), Pos: f.Pos, LeadingDoc: strings.TrimSpace(f.Doc), LineComment: strings.TrimSpace(f.Comment)}, true
+}
+
+func (s *Type) FieldLeadingDocs() map[string]string {
+	docs := make(map[string]string, len(s.Raw.Fields))
+	for _, f := range s.Raw.Fields {
+		docs[f.Name] = strings.TrimSpace(f.Doc)
+	}
+	return docs
+}
+
+func (s *Type) FieldLineComments() map[string]string {
+	comments := make(map[string]string, len(s.Raw.Fields))
+	for _, f := range s.Raw.Fields {
+		comments[f.Name] = strings.TrimSpace(f.Comment)
+	}
+	return comments
+}
+
+func (s *Type) FieldPositions() map[string]token.Pos {
+	positions := make(map[string]token.Pos, len(s.Raw.Fields))
+	for _, f := range s.Raw.Fields {
+		positions[f.Name] = f.Pos
+	}
+	return positions
+}
+
 func (l *Lookup) LookupFromType(ob interface{}) (*Type, error) {
 	rt := reflect.TypeOf(ob)
 	return l.LookupFromTypeForReflectType(rt)
 }
 func (l *Lookup) LookupFromTypeForReflectType(rt reflect.Type) (*Type, error) {
-	obname, _, _ := strings.Cut(rt.Name(), "[") // for generics
-	pkgpath := rt.PkgPath()
+	return l.LookupFromTypeName(rt.PkgPath(), rt.Name())
+}
 
+// LookupFromTypeName returns the metadata of the type declared in the package.
+func (l *Lookup) LookupFromTypeName(pkgpath string, name string) (*Type, error) {
+	obname, _, _ := strings.Cut(name, "[") // for generics
 	if pkgpath == "main" {
 		binfo, ok := debug.ReadBuildInfo()
 		if !ok {
@@ -314,18 +435,18 @@ func (l *Lookup) LookupFromTypeForReflectType(rt reflect.Type) (*Type, error) {
 		if !ok {
 			result, ok = p.Interfaces[obname]
 			if !ok {
-				return nil, fmt.Errorf("lookup metadata of %v is failed %w", rt, ErrNotFound)
+				return nil, fmt.Errorf("lookup metadata of %s.%s is failed %w", pkgpath, name, ErrNotFound)
 			}
 		}
 		if DEBUG {
 			log.Println("OK package cache", pkgpath)
 		}
-		return &Type{Raw: result}, nil
+		return &Type{Raw: result, PkgPath: pkgpath}, nil
 	}
 
 	cfg := &packages.Config{
 		Fset:  l.Fset,
-		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedModule,
 		Tests: l.IncludeGoTestFiles, // TODO: support <name>_test package
 		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
 			// TODO: debug print
@@ -351,18 +472,23 @@ func (l *Lookup) LookupFromTypeForReflectType(rt reflect.Type) (*Type, error) {
 			continue
 		}
 
+		l.addPackage(pkg)
 		if pkg.PkgPath != pkgpath {
 			continue
 		}
 		tree := &ast.Package{Name: pkg.Name, Files: map[string]*ast.File{}}
 		for _, f := range pkg.Syntax {
-			filename := l.Fset.File(f.Pos()).Name()
-			tree.Files[filename] = f
+			tf := l.Fset.File(f.Pos())
+			tree.Files[tf.Name()] = f
+			l.files[tf] = f
 		}
 
 		ref := &packageRef{fullset: true}
 		l.cache[pkg.PkgPath] = ref
 		p, err := commentof.Package(l.Fset, tree, commentof.WithIncludeUnexported(l.IncludeUnexported))
+		if err == nil {
+			err = l.collectGenericMethods(p, pkg.Syntax)
+		}
 		if err != nil {
 			ref.err = err
 			return nil, fmt.Errorf("collect: dir=%s, name=%s, %w", pkg.PkgPath, obname, err)
@@ -379,9 +505,473 @@ func (l *Lookup) LookupFromTypeForReflectType(rt reflect.Type) (*Type, error) {
 		if DEBUG {
 			log.Println("NG package cache", pkgpath)
 		}
-		return &Type{Raw: result}, nil
+		return &Type{Raw: result, PkgPath: pkgpath}, nil
+	}
+	return nil, fmt.Errorf("lookup metadata of %s.%s is failed %w", pkgpath, name, ErrNotFound)
+}
+
+// LookupSymbol reports whether the symbol is declared in the package, the symbol is a type, a function (recv is empty) or a method (e.g. the target of doc links, [Name] and [Recv.Name]).
+func (l *Lookup) LookupSymbol(pkgpath string, recv string, name string) bool {
+	typename := recv
+	if typename == "" {
+		typename = name
+	}
+	if _, err := l.LookupFromTypeName(pkgpath, typename); err == nil && recv == "" {
+		return true
+	}
+
+	p, ok := l.cache[pkgpath] // loaded by LookupFromTypeName()
+	if !ok || !p.fullset || p.Package == nil {
+		return false
+	}
+	if recv == "" {
+		_, ok := p.Functions[name]
+		return ok
+	}
+	if ob, ok := p.Types[recv]; ok {
+		_, ok := ob.Methods[name]
+		return ok
+	}
+	if ob, ok := p.Interfaces[recv]; ok {
+		_, ok := ob.Fields[name] // interface's methods are collected as fields
+		return ok
+	}
+	return false
+}
+
+// LookupEmbeddedInterfaces returns the metadata of the interfaces embedded in the interface (only direct ones).
+func (l *Lookup) LookupEmbeddedInterfaces(iface *Type) ([]*Type, error) {
+	var r []*Type
+	for _, id := range iface.Raw.FieldNames {
+		field := iface.Raw.Fields[id]
+		if !field.Embedded {
+			continue
+		}
+		pkgpath, name, ok := l.resolveTypeName(iface, field.Name)
+		if !ok {
+			return nil, fmt.Errorf("resolve embedded interface %s in %s, %w", field.Name, iface.Name(), ErrNotFound)
+		}
+		t, err := l.LookupFromTypeName(pkgpath, name)
+		if err != nil {
+			return nil, fmt.Errorf("lookup embedded interface %s in %s, %w", field.Name, iface.Name(), err)
+		}
+		r = append(r, t)
+	}
+	return r, nil
+}
+
+// LookupMethodOwner returns the metadata of the interface that declares the method, following the embedded interfaces.
+func (l *Lookup) LookupMethodOwner(iface *Type, name string) (*Type, error) {
+	if field, ok := iface.Raw.Fields[name]; ok && !field.Embedded {
+		return iface, nil
+	}
+	embedded, err := l.LookupEmbeddedInterfaces(iface)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range embedded {
+		if owner, err := l.LookupMethodOwner(t, name); err == nil {
+			return owner, nil
+		}
+	}
+	return nil, fmt.Errorf("lookup owner of method %s.%s, %w", iface.Name(), name, ErrNotFound)
+}
+
+// resolveTypeName resolves the type name in the file that the type is declared (e.g. "io.Reader" -> "io", "Reader").
+func (l *Lookup) resolveTypeName(t *Type, typename string) (pkgpath string, name string, ok bool) {
+	qualifier, name, qualified := strings.Cut(typename, ".")
+	if !qualified {
+		return t.PkgPath, typename, true
+	}
+
+	tf := l.Fset.File(t.Raw.Pos)
+	if tf == nil {
+		return "", "", false
+	}
+	f, ok := l.files[tf]
+	if !ok {
+		return "", "", false
+	}
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := GuessPackageName(path)
+		if imp.Name != nil {
+			importName = imp.Name.Name
+		}
+		if importName == qualifier {
+			return path, name, true
+		}
+	}
+	return "", "", false
+}
+
+// GuessPackageName guesses the package name from the import path, without the source code.
+// (e.g. "gopkg.in/yaml.v3" -> "yaml", "example.com/foo/v2" -> "foo", "github.com/mattn/go-sqlite3" -> "sqlite3")
+func GuessPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && isMajorVersion(name) {
+		name = parts[len(parts)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// LookupFromInterfaceMethod returns the metadata of the method declared in the interface.
+// The names and comments of the args and returns are taken from the interface declaration.
+// If the method is declared in the embedded interface, the metadata is taken from it.
+func (l *Lookup) LookupFromInterfaceMethod(iface *Type, name string) (*Func, error) {
+	iface, err := l.LookupMethodOwner(iface, name)
+	if err != nil {
+		return nil, err
+	}
+	field := iface.Raw.Fields[name]
+	tf := l.Fset.File(field.Pos)
+	if tf == nil {
+		return nil, fmt.Errorf("lookup metadata of method %s.%s, %w", iface.Name(), name, ErrNotFound)
+	}
+	f, ok := l.files[tf]
+	if !ok {
+		return nil, fmt.Errorf("lookup metadata of method %s.%s, %w", iface.Name(), name, ErrNotFound)
+	}
+
+	var method *ast.Field
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || method != nil || n.End() <= field.Pos || n.Pos() > field.Pos {
+			return false
+		}
+		if x, ok := n.(*ast.Field); ok && x.Pos() == field.Pos {
+			if _, ok := x.Type.(*ast.FuncType); ok && len(x.Names) > 0 {
+				method = x
+				return false
+			}
+		}
+		return true
+	})
+	if method == nil {
+		return nil, fmt.Errorf("lookup metadata of method %s.%s, %w", iface.Name(), name, ErrNotFound)
+	}
+
+	// collect as func declaration: func <name>(<params>) <results>
+	c := &collect.Collector{Fset: l.Fset, Dot: ".", Sharp: "#"}
+	cf := collect.NewFile()
+	decl := &ast.FuncDecl{Doc: method.Doc, Name: method.Names[0], Type: method.Type.(*ast.FuncType)}
+	if err := c.CollectFromFuncDecl(cf, f, decl); err != nil {
+		return nil, fmt.Errorf("collect method %s.%s, %w", iface.Name(), name, err)
+	}
+	result := cf.Functions[name]
+	result.Pos = method.Pos()
+	result.Recv = iface.Name()
+	result.Doc = field.Doc
+	if result.Doc == "" {
+		result.Doc = field.Comment
+	}
+	return &Func{Raw: result, Recv: iface.Name()}, nil
+}
+
+// collectGenericMethods collects the methods of generic types (e.g. func (l *List[T]) Push(v T)).
+// commentof treats them as functions, because the receiver type is not identifier.
+func (l *Lookup) collectGenericMethods(p *collect.Package, files []*ast.File) error {
+	c := &collect.Collector{Fset: l.Fset, Dot: ".", Sharp: "#"}
+	for _, f := range files {
+		filename := l.Fset.File(f.Pos()).Name()
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv, ok := genericRecvName(decl.Recv.List[0].Type)
+			if !ok {
+				continue
+			}
+			name := decl.Name.Name
+			if !l.IncludeUnexported && !ast.IsExported(name) {
+				continue
+			}
+			ob, ok := p.Types[strings.TrimPrefix(recv, "*")]
+			if !ok {
+				continue
+			}
+
+			cf := collect.NewFile()
+			if err := c.CollectFromFuncDecl(cf, f, decl); err != nil {
+				return fmt.Errorf("collect method %s.%s, %w", recv, name, err)
+			}
+			m := cf.Functions[name]
+			m.Recv = recv
+			if _, ok := ob.Methods[name]; !ok {
+				ob.MethodNames = append(ob.MethodNames, name)
+			}
+			ob.Methods[name] = m
+
+			// remove the wrongly collected function
+			if fn, ok := p.Functions[name]; ok && fn.Pos == decl.Pos() {
+				delete(p.Functions, name)
+			}
+			if f, ok := p.Files[filename]; ok {
+				if fn, ok := f.Functions[name]; ok && fn.Pos == decl.Pos() {
+					delete(f.Functions, name)
+				}
+			}
+		}
+	}
+
+	// restore the functions overwritten by the methods that have the same name
+	for _, f := range files {
+		filename := l.Fset.File(f.Pos()).Name()
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil {
+				continue
+			}
+			name := decl.Name.Name
+			if _, ok := p.Functions[name]; ok || (!l.IncludeUnexported && !ast.IsExported(name)) {
+				continue
+			}
+			cf := collect.NewFile()
+			if err := c.CollectFromFuncDecl(cf, f, decl); err != nil {
+				return fmt.Errorf("collect function %s, %w", name, err)
+			}
+			p.Functions[name] = cf.Functions[name]
+			if f, ok := p.Files[filename]; ok {
+				f.Functions[name] = cf.Functions[name]
+			}
+		}
+	}
+	return nil
+}
+
+// genericRecvName returns the name of the generic receiver type (e.g. *List[T] -> "*List").
+func genericRecvName(typ ast.Expr) (string, bool) {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		name, ok := genericRecvName(t.X)
+		return "*" + name, ok
+	case *ast.IndexExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		return ident.Name, true
+	case *ast.IndexListExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		return ident.Name, true
+	default:
+		return "", false
+	}
+}
+
+// CommentGroups returns the raw comment groups around the declaration at pos.
+// doc is the comment group that ends on the previous line, and comment is the comment group that starts on the same line.
+// Unlike the Doc() methods, the directive comments (e.g. "//go:generate") are not dropped.
+func (l *Lookup) CommentGroups(pos token.Pos) (doc *ast.CommentGroup, comment *ast.CommentGroup) {
+	if !pos.IsValid() {
+		return nil, nil
+	}
+	tf := l.Fset.File(pos)
+	if tf == nil {
+		return nil, nil
+	}
+	idx := l.commentIndex(tf)
+	if idx == nil {
+		return nil, nil
+	}
+
+	line := tf.Line(pos)
+	hasDoc := !idx.precededOnLine(line, pos) // e.g. the 2nd parameter of func(x int, y int)
+	start := sort.Search(len(idx.comments), func(i int) bool { return tf.Line(idx.comments[i].End()) >= line-1 })
+	for _, cg := range idx.comments[start:] {
+		switch {
+		case hasDoc && tf.Line(cg.End()) == line-1:
+			if !idx.precededOnLine(tf.Line(cg.Pos()), cg.Pos()) { // not a line comment of the previous line
+				doc = cg
+			}
+		case tf.Line(cg.Pos()) == line && cg.Pos() > pos:
+			comment = cg
+			return doc, comment
+		case tf.Line(cg.Pos()) > line:
+			return doc, comment
+		}
+	}
+	return doc, comment
+}
+
+// commentIndex is the index of the file for CommentGroups(), built once per file.
+type commentIndex struct {
+	comments   []*ast.CommentGroup
+	firstNodes map[int]token.Pos // line -> the position of the first node that starts on the line
+}
+
+func (l *Lookup) commentIndex(tf *token.File) *commentIndex {
+	if idx, ok := l.comments[tf]; ok {
+		return idx
+	}
+	f, ok := l.files[tf]
+	if !ok {
+		return nil
+	}
+
+	idx := &commentIndex{comments: f.Comments, firstNodes: map[int]token.Pos{}}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		line := tf.Line(n.Pos())
+		if first, ok := idx.firstNodes[line]; !ok || n.Pos() < first {
+			idx.firstNodes[line] = n.Pos()
+		}
+		return true
+	})
+	l.comments[tf] = idx
+	return idx
+}
+
+// precededOnLine reports whether any node starts before pos on the line.
+func (idx *commentIndex) precededOnLine(line int, pos token.Pos) bool {
+	first, ok := idx.firstNodes[line]
+	return ok && first < pos
+}
+
+// Package is the metadata of package.
+type Package struct {
+	Name   string
+	Path   string
+	Dir    string  // the directory containing the package's source files
+	Module *Module // nil, if the package is not in module (e.g. standard library)
+}
+
+// Module is the module that the package belongs to.
+type Module struct {
+	Path    string
+	Version string  // empty, if the module is main module or replaced by local directory
+	Replace *Module // replaced by this module
+	Dir     string  // the directory holding the module's files, if any
+	Main    bool    // if true, the module is main module
+}
+
+// LookupPackage returns the metadata of the package (name, directory and module).
+func (l *Lookup) LookupPackage(pkgpath string) (*Package, error) {
+	if pkg, ok := l.pkgs[pkgpath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("lookup package %s, %w", pkgpath, ErrNotFound)
+		}
+		return pkg, nil
+	}
+
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedModule,
+		Tests: l.IncludeGoTestFiles,
+	}
+	pattern := strings.TrimSuffix(pkgpath, "_test") // for go test
+	if pkgpath == "main" {
+		binfo, ok := debug.ReadBuildInfo()
+		if !ok {
+			return nil, fmt.Errorf("lookup package %s, debug.ReadBuildInfo() is failed, %w", pkgpath, ErrNotFound)
+		}
+		pattern = binfo.Path
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("packages.Load() %w", err)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			l.addPackage(pkg)
+		}
+	}
+	if pkgpath == "main" && len(pkgs) > 0 && len(pkgs[0].Errors) == 0 {
+		l.pkgs[pkgpath] = l.pkgs[pkgs[0].PkgPath]
+	}
+
+	pkg, ok := l.pkgs[pkgpath]
+	if !ok {
+		l.pkgs[pkgpath] = nil // negative cache
+		return nil, fmt.Errorf("lookup package %s, %w", pkgpath, ErrNotFound)
+	}
+	return pkg, nil
+}
+
+func (l *Lookup) addPackage(pkg *packages.Package) {
+	if pkg.Name == "" {
+		return
+	}
+	if _, ok := l.pkgs[pkg.PkgPath]; ok && strings.HasSuffix(pkg.ID, ".test]") {
+		return // prefer the package without test files (e.g. "<pkg> [<pkg>.test]")
+	}
+
+	p := &Package{Name: pkg.Name, Path: pkg.PkgPath, Module: toModule(pkg.Module)}
+	if len(pkg.GoFiles) > 0 {
+		p.Dir = filepath.Dir(pkg.GoFiles[0])
+	}
+	if p.Module == nil {
+		p.Module = ModuleFromBuildInfo(pkg.PkgPath)
+	}
+	l.pkgs[pkg.PkgPath] = p
+}
+
+func toModule(m *packages.Module) *Module {
+	if m == nil {
+		return nil
+	}
+	return &Module{Path: m.Path, Version: m.Version, Replace: toModule(m.Replace), Dir: m.Dir, Main: m.Main}
+}
+
+// ModuleFromBuildInfo returns the module of the package from the build information embedded in the running binary.
+// If the module is not found (e.g. standard library), returns nil.
+func ModuleFromBuildInfo(pkgpath string) *Module {
+	binfo, ok := debug.ReadBuildInfo()
+	if !ok || pkgpath == "" {
+		return nil
+	}
+	if pkgpath == "main" || inModule(pkgpath, binfo.Main.Path) {
+		version := binfo.Main.Version
+		if version == "(devel)" {
+			version = ""
+		}
+		return &Module{Path: binfo.Main.Path, Version: version, Main: true}
+	}
+
+	var found *debug.Module
+	for _, dep := range binfo.Deps {
+		if inModule(pkgpath, dep.Path) && (found == nil || len(found.Path) < len(dep.Path)) {
+			found = dep
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	m := &Module{Path: found.Path, Version: found.Version}
+	if found.Replace != nil {
+		m.Replace = &Module{Path: found.Replace.Path, Version: found.Replace.Version}
+	}
+	return m
+}
+
+func inModule(pkgpath string, modpath string) bool {
+	if modpath == "" {
+		return false
 	}
-	return nil, fmt.Errorf("lookup metadata of %v is failed %w", rt, ErrNotFound)
+	pkgpath = strings.TrimSuffix(pkgpath, "_test")
+	return pkgpath == modpath || strings.HasPrefix(pkgpath, modpath+"/")
 }
 
 type packageRef struct {
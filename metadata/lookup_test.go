@@ -4,6 +4,7 @@ import (
 	"context"
 	"go/token"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -220,3 +221,187 @@ func TestInterface(t *testing.T) {
 		})
 	}
 }
+
+// Greeter is Greeter
+type Greeter interface {
+	// Greet returns greeting message
+	Greet(ctx context.Context, name string) (message string)
+}
+
+func TestInterfaceMethod(t *testing.T) {
+	type result struct {
+		Name    string
+		Doc     string
+		Args    []string
+		Returns []string
+	}
+
+	want := result{
+		Name:    "Greet",
+		Doc:     "Greet returns greeting message",
+		Args:    []string{"ctx", "name"},
+		Returns: []string{"message"},
+	}
+
+	fset := token.NewFileSet()
+	l := NewLookup(fset)
+	l.IncludeGoTestFiles = true
+
+	iface, err := l.LookupFromTypeForReflectType(reflect.TypeOf(func() Greeter { return nil }).Out(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	metadata, err := l.LookupFromInterfaceMethod(iface, "Greet")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	got := result{Name: metadata.Name(), Doc: metadata.Doc()}
+	for _, p := range metadata.Args() {
+		got.Args = append(got.Args, p.Name)
+	}
+	for _, p := range metadata.Returns() {
+		got.Returns = append(got.Returns, p.Name)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LookupFromInterfaceMethod() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Put is the function that has the same name with Box.Put
+func Put() {}
+
+type Box[T any] struct{}
+
+// Put puts the value
+func (b *Box[T]) Put(value T) (ok bool) { return true }
+
+func TestGenericMethod(t *testing.T) {
+	type result struct {
+		Name    string
+		Doc     string
+		Recv    string
+		Args    []string
+		Returns []string
+	}
+
+	want := result{
+		Name:    "Put",
+		Doc:     "Put puts the value",
+		Recv:    "Box",
+		Args:    []string{"value"},
+		Returns: []string{"ok"},
+	}
+
+	fset := token.NewFileSet()
+	l := NewLookup(fset)
+	l.IncludeGoTestFiles = true
+
+	metadata, err := l.LookupFromFunc((*Box[int]).Put)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	got := result{Name: metadata.Name(), Doc: metadata.Doc(), Recv: metadata.Recv}
+	for _, p := range metadata.Args() {
+		got.Args = append(got.Args, p.Name)
+	}
+	for _, p := range metadata.Returns() {
+		got.Returns = append(got.Returns, p.Name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LookupFromFunc() mismatch (-want +got):\n%s", diff)
+	}
+
+	// the function is not overwritten by the method
+	fn, err := l.LookupFromFunc(Put)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if want, got := "Put is the function that has the same name with Box.Put", fn.Doc(); want != got {
+		t.Errorf("LookupFromFunc() doc: want:%q != got:%q", want, got)
+	}
+}
+
+// Take is the function that has the same name with Bag.Take (in generic_test.go)
+func Take() {}
+
+func TestGenericMethodInAnotherFile(t *testing.T) {
+	fset := token.NewFileSet()
+	l := NewLookup(fset)
+	l.IncludeGoTestFiles = true
+
+	// lookup_test.go is parsed before generic_test.go
+	if _, err := l.LookupFromFunc(Take); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	method, err := l.LookupFromFunc((*Bag[int]).Take)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if want, got := "Take takes the value", method.Doc(); want != got {
+		t.Errorf("LookupFromFunc() doc: want:%q != got:%q", want, got)
+	}
+
+	// the function of the earlier file is not overwritten by the method
+	p := l.cache[reflect.TypeOf(Bag[int]{}).PkgPath()]
+	fn, ok := p.Functions["Take"]
+	if !ok {
+		t.Fatalf("the function Take is lost")
+	}
+	if want, got := "Take is the function that has the same name with Bag.Take (in generic_test.go)", strings.TrimSpace(fn.Doc); want != got {
+		t.Errorf("function doc: want:%q != got:%q", want, got)
+	}
+}
+
+func TestLookupSymbol(t *testing.T) {
+	fset := token.NewFileSet()
+	l := NewLookup(fset)
+	l.IncludeGoTestFiles = true
+
+	pkgpath := reflect.TypeOf(S{}).PkgPath()
+	cases := []struct {
+		recv string
+		name string
+		want bool
+	}{
+		{name: "Person", want: true},
+		{name: "I", want: true},
+		{name: "Hello", want: true},
+		{recv: "S", name: "Method1", want: true},
+		{recv: "I", name: "Foo", want: true},
+		{recv: "Person", name: "Name", want: false}, // field
+		{name: "Undeclared", want: false},
+		{recv: "S", name: "Undeclared", want: false},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.recv+"."+c.name, func(t *testing.T) {
+			if got := l.LookupSymbol(pkgpath, c.recv, c.name); c.want != got {
+				t.Errorf("LookupSymbol(): want:%v != got:%v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "fmt", want: "fmt"},
+		{path: "net/http", want: "http"},
+		{path: "gopkg.in/yaml.v3", want: "yaml"},
+		{path: "github.com/go-chi/chi/v5", want: "chi"},
+		{path: "github.com/mattn/go-sqlite3", want: "sqlite3"},
+		{path: "github.com/podhmo/reflect-shape", want: "reflect"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.path, func(t *testing.T) {
+			if got := GuessPackageName(c.path); c.want != got {
+				t.Errorf("GuessPackageName(): want:%q != got:%q", c.want, got)
+			}
+		})
+	}
+}
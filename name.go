@@ -0,0 +1,43 @@
+package reflectshape
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitWords splits the Go identifier into words (e.g. "MaxConns" -> ["Max", "Conns"], "DBHost" -> ["DB", "Host"]).
+func splitWords(name string) []string {
+	var r []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_' || cur == '-':
+			if start < i {
+				r = append(r, string(runes[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)),
+			unicode.IsUpper(cur) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]): // "DBHost" -> "DB", "Host"
+			if start < i {
+				r = append(r, string(runes[start:i]))
+			}
+			start = i
+		}
+	}
+	if start < len(runes) {
+		r = append(r, string(runes[start:]))
+	}
+	return r
+}
+
+// kebabCase returns the kebab-case name of the Go identifier (e.g. "MaxConns" -> "max-conns").
+func kebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// screamingSnakeCase returns the SCREAMING_SNAKE_CASE name of the Go identifier (e.g. "MaxConns" -> "MAX_CONNS").
+func screamingSnakeCase(name string) string {
+	return strings.ToUpper(strings.Join(splitWords(name), "_"))
+}
@@ -0,0 +1,180 @@
+package reflectshape
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+type RedactOptions struct {
+	Tag        string // the tag name of sensitive fields, default is "secret" (e.g. `secret:"true"`)
+	Annotation string // the annotation name of sensitive fields, default is "secret" (e.g. "// @secret", if Config.AnnotationPrefixes includes "@")
+	Mask       string // the masked value of string fields, default is "*****"
+}
+
+// Redact returns the copy of ob (T or *T, the value of the shape) with the sensitive fields masked, it can be used for logging.
+// The string fields (and the pointers, slices and arrays of strings) are replaced with the mask, and the other fields are set to zero.
+// The nested structs, pointers, slices, maps and interfaces are followed.
+//
+// The sensitive unexported fields are also masked, but the other unexported fields are copied as-is (the values referenced by them are shared with ob).
+func (s *Shape) Redact(ob interface{}, options RedactOptions) (interface{}, error) {
+	if options.Tag == "" {
+		options.Tag = "secret"
+	}
+	if options.Annotation == "" {
+		options.Annotation = "secret"
+	}
+	if options.Mask == "" {
+		options.Mask = "*****"
+	}
+
+	rv := reflect.ValueOf(ob)
+	if !rv.IsValid() {
+		return ob, nil
+	}
+	if rt := rv.Type(); rt != s.Type && !(rt.Kind() == reflect.Pointer && rt.Elem() == s.Type) {
+		return nil, fmt.Errorf("unexpected value %T, %v or *%v is expected", ob, s.Type, s.Type)
+	}
+
+	defer s.e.query()()
+	r := &redactor{e: s.e, options: options, secrets: map[reflect.Type][]bool{}, pointers: map[redactKey]reflect.Value{}}
+	return r.redact(rv).Interface(), nil
+}
+
+type redactor struct {
+	e        *Extractor
+	options  RedactOptions
+	secrets  map[reflect.Type][]bool     // the sensitive flags of the struct fields
+	pointers map[redactKey]reflect.Value // the copied pointers (for cyclic references)
+}
+
+// redactKey is the key of the copied pointers, the address is not enough (e.g. the pointer of the struct and the pointer of its first field).
+type redactKey struct {
+	rt  reflect.Type
+	ptr uintptr
+}
+
+func (r *redactor) redact(rv reflect.Value) reflect.Value {
+	rt := rv.Type()
+	switch rt.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return rv
+		}
+		k := redactKey{rt: rt, ptr: rv.Pointer()}
+		if copied, ok := r.pointers[k]; ok { // cyclic
+			return copied
+		}
+		copied := reflect.New(rt.Elem())
+		r.pointers[k] = copied
+		copied.Elem().Set(r.redact(rv.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(rt).Elem()
+		copied.Set(rv) // for unexported fields (copied as-is)
+		secrets := r.secretFields(rt)
+		for i := 0; i < rt.NumField(); i++ {
+			if !rt.Field(i).IsExported() {
+				if secrets[i] {
+					f := copied.Field(i) // addressable, but not settable
+					reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Set(r.mask(rv.Field(i)))
+				}
+				continue
+			}
+			if secrets[i] {
+				copied.Field(i).Set(r.mask(rv.Field(i)))
+				continue
+			}
+			copied.Field(i).Set(r.redact(rv.Field(i)))
+		}
+		return copied
+	case reflect.Slice:
+		if rv.IsNil() {
+			return rv
+		}
+		copied := reflect.MakeSlice(rt, rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			copied.Index(i).Set(r.redact(rv.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(rt).Elem()
+		for i := 0; i < rv.Len(); i++ {
+			copied.Index(i).Set(r.redact(rv.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if rv.IsNil() {
+			return rv
+		}
+		copied := reflect.MakeMapWithSize(rt, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), r.redact(iter.Value()))
+		}
+		return copied
+	case reflect.Interface:
+		if rv.IsNil() {
+			return rv
+		}
+		copied := reflect.New(rt).Elem()
+		copied.Set(r.redact(rv.Elem()))
+		return copied
+	default:
+		return rv
+	}
+}
+
+// mask returns the masked value of the sensitive field.
+func (r *redactor) mask(rv reflect.Value) reflect.Value {
+	rt := rv.Type()
+	switch rt.Kind() {
+	case reflect.String:
+		copied := reflect.New(rt).Elem()
+		copied.SetString(r.options.Mask)
+		return copied
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return reflect.Zero(rt) // not rv, it may be read from the unexported field
+		}
+		copied := reflect.New(rt.Elem())
+		copied.Elem().Set(r.mask(rv.Elem()))
+		return copied
+	case reflect.Slice:
+		if rv.IsNil() {
+			return reflect.Zero(rt) // not rv, it may be read from the unexported field
+		}
+		copied := reflect.MakeSlice(rt, rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			copied.Index(i).Set(r.mask(rv.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(rt).Elem()
+		for i := 0; i < rv.Len(); i++ {
+			copied.Index(i).Set(r.mask(rv.Index(i)))
+		}
+		return copied
+	default:
+		return reflect.Zero(rt)
+	}
+}
+
+// secretFields returns the sensitive flags of the struct fields, marked by the tag or the annotation.
+func (r *redactor) secretFields(rt reflect.Type) []bool {
+	if flags, ok := r.secrets[rt]; ok {
+		return flags
+	}
+
+	flags := make([]bool, rt.NumField())
+	for i, f := range r.e.extract(rt, rzero(rt)).Struct().Fields() {
+		if v, ok := f.Tag.Lookup(r.options.Tag); ok {
+			flags[i], _ = strconv.ParseBool(v)
+			continue
+		}
+		_, flags[i] = f.Annotations.Lookup(r.options.Annotation)
+	}
+	r.secrets[rt] = flags
+	return flags
+}
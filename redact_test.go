@@ -0,0 +1,134 @@
+package reflectshape_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+type Credential struct {
+	User     string
+	Password string `secret:"true"`
+	// Token is the API token.
+	// @secret
+	Token *string
+	Keys  []string `secret:"true"`
+	PIN   int      `secret:"true"`
+	Note  string   `secret:"false"`
+}
+
+type Deployment struct {
+	Name   string
+	Cred   Credential
+	Backup *Credential
+	Others []Credential
+	ByEnv  map[string]*Credential
+	Meta   any
+}
+
+type Session struct {
+	Cred   *Credential
+	User   *string // may point to Cred.User (same address as Cred)
+	secret string  `secret:"true"`
+	token  *string
+}
+
+func TestRedact(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true, AnnotationPrefixes: []string{"@"}}
+	token := "t0ken"
+	newCredential := func() Credential {
+		return Credential{User: "foo", Password: "pa55", Token: &token, Keys: []string{"k1", "k2"}, PIN: 1234, Note: "memo"}
+	}
+	redact := func(t *testing.T, ob any, options reflectshape.RedactOptions) any {
+		t.Helper()
+		got, err := cfg.Extract(ob).Redact(ob, options)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		return got
+	}
+	mask := "*****"
+	masked := Credential{User: "foo", Password: mask, Token: &mask, Keys: []string{mask, mask}, PIN: 0, Note: "memo"}
+
+	t.Run("struct", func(t *testing.T) {
+		ob := newCredential()
+		got := redact(t, ob, reflectshape.RedactOptions{})
+		if diff := cmp.Diff(masked, got); diff != "" {
+			t.Errorf("Shape.Redact(): -want, +got: \n%v", diff)
+		}
+		if diff := cmp.Diff(newCredential(), ob); diff != "" { // not modified
+			t.Errorf("the original value is modified: -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		backup := newCredential()
+		ob := &Deployment{
+			Name:   "prod",
+			Cred:   newCredential(),
+			Backup: &backup,
+			Others: []Credential{newCredential()},
+			ByEnv:  map[string]*Credential{"dev": &backup},
+			Meta:   newCredential(),
+		}
+		got := redact(t, ob, reflectshape.RedactOptions{})
+		want := &Deployment{
+			Name:   "prod",
+			Cred:   masked,
+			Backup: &masked,
+			Others: []Credential{masked},
+			ByEnv:  map[string]*Credential{"dev": &masked},
+			Meta:   masked,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Shape.Redact(): -want, +got: \n%v", diff)
+		}
+		if want, got := "pa55", ob.Backup.Password; want != got { // not modified
+			t.Errorf("the original value is modified: %q != %q", want, got)
+		}
+	})
+
+	t.Run("pointer-to-first-field", func(t *testing.T) {
+		cred := newCredential()
+		ob := &Session{Cred: &cred, User: &cred.User}
+		got := redact(t, ob, reflectshape.RedactOptions{}).(*Session)
+		if diff := cmp.Diff(&masked, got.Cred); diff != "" {
+			t.Errorf("Shape.Redact().Cred: -want, +got: \n%v", diff)
+		}
+		if want, got := "foo", *got.User; want != got {
+			t.Errorf("Shape.Redact().User: %q != %q", want, got)
+		}
+	})
+
+	t.Run("unexported", func(t *testing.T) {
+		ob := &Session{secret: "pa55", token: &token}
+		got := redact(t, ob, reflectshape.RedactOptions{}).(*Session)
+		if want, got := mask, got.secret; want != got { // masked, even if unexported
+			t.Errorf("Shape.Redact().secret: %q != %q", want, got)
+		}
+		if want, got := "pa55", ob.secret; want != got { // not modified
+			t.Errorf("the original value is modified: %q != %q", want, got)
+		}
+		if want, got := &token, got.token; want != got { // not sensitive, copied as-is (shared)
+			t.Errorf("Shape.Redact().token: %p != %p", want, got)
+		}
+	})
+
+	t.Run("unexpected-type", func(t *testing.T) {
+		if _, err := cfg.Extract(Credential{}).Redact(&Session{}, reflectshape.RedactOptions{}); err == nil {
+			t.Errorf("Shape.Redact(): the error is expected for the value of the other type")
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		ob := newCredential()
+		got := redact(t, ob, reflectshape.RedactOptions{Mask: "xxx", Annotation: "sensitive"}).(Credential)
+		if want, got := "xxx", got.Password; want != got {
+			t.Errorf("Password: %q != %q", want, got)
+		}
+		if want, got := "t0ken", *got.Token; want != got { // not annotated with @sensitive
+			t.Errorf("Token: %q != %q", want, got)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+// Package reflectshapetest provides the helpers for testing with reflect-shape (golden files and go-cmp options).
+package reflectshapetest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// the flag name is namespaced, not to conflict with the -update flag of the package under test.
+var update = flag.Bool("reflectshape.update", false, "update the golden files of reflectshapetest")
+
+// Update is the switch to update the golden files, in addition to the -reflectshape.update flag.
+var Update = false
+
+// GoldenDir is the directory of the golden files.
+var GoldenDir = "testdata"
+
+// AssertShape compares the canonical text form of the shape with the golden file (<GoldenDir>/<name>.golden).
+// If the -reflectshape.update flag is passed (or Update is true), the golden file is updated.
+func AssertShape(t testing.TB, s *reflectshape.Shape, name string) {
+	t.Helper()
+	AssertGolden(t, Render(s), name)
+}
+
+// AssertVisited compares the canonical text form of the whole visited graph with the golden file (<GoldenDir>/<name>.golden).
+// If the -reflectshape.update flag is passed (or Update is true), the golden file is updated.
+func AssertVisited(t testing.TB, c *reflectshape.Config, name string) {
+	t.Helper()
+	AssertGolden(t, RenderSnapshot(c.Snapshot()), name)
+}
+
+// AssertGolden compares the text with the golden file (<GoldenDir>/<name>.golden).
+// If the -reflectshape.update flag is passed (or Update is true), the golden file is updated.
+func AssertGolden(t testing.TB, got string, name string) {
+	t.Helper()
+	filename := filepath.Join(GoldenDir, name+".golden")
+	if Update || *update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("mkdir: %+v", err)
+		}
+		if err := os.WriteFile(filename, []byte(got), 0644); err != nil {
+			t.Fatalf("update golden file: %+v", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read golden file (if it is the first time, run with -reflectshape.update): %+v", err)
+	}
+	if diff := cmp.Diff(string(b), got); diff != "" {
+		t.Errorf("golden file %s: -want, +got: \n%v", filename, diff)
+	}
+}
+
+// Render returns the canonical text form of the shape. Unlike Shape.String(), the docs are not truncated.
+func Render(s *reflectshape.Shape) string {
+	var b strings.Builder
+	writeShape(&b, s.Snapshot())
+	return b.String()
+}
+
+// RenderSnapshot returns the canonical text form of the snapshot.
+func RenderSnapshot(ss *reflectshape.Snapshot) string {
+	var b strings.Builder
+	for i, s := range ss.Shapes {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		writeShape(&b, s)
+	}
+	return b.String()
+}
+
+func writeShape(b *strings.Builder, s *reflectshape.ShapeSnapshot) {
+	fmt.Fprintf(b, "# %s (%s)\n", s.Name, s.Kind)
+	writeDoc(b, "", s.Doc)
+	if s.Position != "" {
+		fmt.Fprintf(b, "position: %s\n", position(s.Position))
+	}
+	fmt.Fprintf(b, "type: %s\n", s.Type)
+	writeVars(b, "fields", s.Fields, " ")
+	writeVars(b, "methods", s.Methods, "")
+	writeVars(b, "args", s.Args, " ")
+	writeVars(b, "returns", s.Returns, " ")
+	if s.Variadic {
+		b.WriteString("variadic: true\n")
+	}
+}
+
+func writeVars(b *strings.Builder, title string, vars []*reflectshape.VarSnapshot, sep string) {
+	if len(vars) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", title)
+	for _, v := range vars {
+		writeDoc(b, "\t", v.Doc)
+		b.WriteByte('\t')
+		if v.Name != "" {
+			b.WriteString(v.Name)
+			b.WriteString(sep)
+		}
+		b.WriteString(v.Type)
+		if v.Tag != "" {
+			fmt.Fprintf(b, " `%s`", v.Tag)
+		}
+		b.WriteByte('\n')
+	}
+}
+
+func writeDoc(b *strings.Builder, indent string, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		b.WriteString(indent)
+		b.WriteString(strings.TrimRight("// "+line, " "))
+		b.WriteByte('\n')
+	}
+}
+
+// position returns the file name of the position, without the directory and the line:column
+// (the golden files must not depend on the machine, or be changed by the unrelated edits of the source code).
+func position(pos string) string {
+	name, _, _ := strings.Cut(filepath.Base(pos), ":")
+	return name
+}
+
+// CmpOptions returns the go-cmp options for the values including shapes.
+// The shapes and the types are compared by identity (for shapes, Shape.ID and the pointer level), and the internal states (e.g. the extractor) are ignored.
+func CmpOptions() cmp.Options {
+	return cmp.Options{
+		cmp.Comparer(func(x, y *reflectshape.Shape) bool {
+			if x == nil || y == nil {
+				return x == y
+			}
+			return x.Equal(y) && x.Lv == y.Lv
+		}),
+		cmp.Comparer(func(x, y reflect.Type) bool { return x == y }),
+		cmpopts.IgnoreUnexported(
+			reflectshape.Config{},
+			reflectshape.Package{},
+			reflectshape.Named{},
+			reflectshape.Struct{},
+			reflectshape.Interface{},
+			reflectshape.Func{},
+			reflectshape.Field{},
+			reflectshape.Var{},
+		),
+		cmpopts.IgnoreTypes(&reflectshape.Extractor{}),
+	}
+}
@@ -0,0 +1,90 @@
+package reflectshapetest_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+	"github.com/podhmo/reflect-shape/reflectshapetest"
+)
+
+// Item is the item of the inventory.
+// This doc is long enough to be truncated by Shape.String().
+type Item struct {
+	// ID of the item.
+	ID   string `json:"id"`
+	Name string `json:"name"` // name of the item
+
+	Tags   []string `json:"tags,omitempty"`
+	Parent *Item    `json:"parent,omitempty"`
+}
+
+// Label returns the label of the item.
+func (i *Item) Label() string { return i.Name }
+
+// Inventory stores items.
+type Inventory interface {
+	// Add adds the item.
+	Add(ctx context.Context, item *Item) error
+}
+
+// NewItem creates the item.
+func NewItem(id string, name string, tags ...string) *Item {
+	return &Item{ID: id, Name: name, Tags: tags}
+}
+
+func TestAssertShape(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	reflectshapetest.AssertShape(t, cfg.Extract(&Item{}), "item")
+	reflectshapetest.AssertShape(t, cfg.Extract(NewItem), "new-item")
+}
+
+func TestAssertVisited(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	cfg.Extract(NewItem)
+	cfg.Extract((*Inventory)(nil))
+	reflectshapetest.AssertVisited(t, cfg, "visited")
+}
+
+// the package under test may have its own -update flag (not conflicted with -reflectshape.update)
+var _ = flag.Bool("update", false, "update the other golden files")
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	defer func(dir string) { reflectshapetest.GoldenDir = dir }(reflectshapetest.GoldenDir)
+	reflectshapetest.GoldenDir = dir
+
+	reflectshapetest.Update = true
+	reflectshapetest.AssertGolden(t, "hello\n", "hello")
+	reflectshapetest.Update = false
+
+	b, err := os.ReadFile(filepath.Join(dir, "hello.golden"))
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if want, got := "hello\n", string(b); want != got {
+		t.Errorf("golden file: %q != %q", want, got)
+	}
+	reflectshapetest.AssertGolden(t, "hello\n", "hello")
+}
+
+func TestCmpOptions(t *testing.T) {
+	cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+	s := cfg.Extract(Item{}).Struct()
+
+	if diff := cmp.Diff(s.Fields(), s.Fields(), reflectshapetest.CmpOptions()); diff != "" {
+		t.Errorf("Struct.Fields(): -want, +got: \n%v", diff)
+	}
+	if diff := cmp.Diff(cfg.Extract(Item{}), cfg.Extract(&Item{}), reflectshapetest.CmpOptions()); diff == "" {
+		t.Errorf("the pointer level must be compared")
+	}
+
+	another := (&reflectshape.Config{IncludeGoTestFiles: true}).Extract(Item{}).Struct()
+	if diff := cmp.Diff(s.Fields()[0].Shape, another.Fields()[0].Shape, reflectshapetest.CmpOptions()); diff != "" { // string
+		t.Errorf("the shapes of the same type must be same: -want, +got: \n%v", diff)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"go/token"
 	"log"
 	"reflect"
-	"regexp"
+	"runtime"
+	"strings"
 
+	"github.com/podhmo/reflect-shape/internal/symbol"
 	"github.com/podhmo/reflect-shape/metadata"
 )
 
@@ -29,6 +31,8 @@ type Shape struct {
 	Lv      int // pointer level. v is 0, *v is 1.
 	Package *Package
 	e       *Extractor
+
+	pos token.Pos // the position of the declaration, if it is known in advance (e.g. interface's method)
 }
 
 func (s *Shape) Equal(another *Shape) bool {
@@ -43,6 +47,55 @@ func (s *Shape) String() string {
 	return fmt.Sprintf("&Shape#%d{Name: %q, Kind: %v, Type: %v, Package: %v}", s.Number, s.Name, s.Kind, s.Type, s.Package.Name)
 }
 
+// IsExported reports whether the shape is exported. For methods, both the receiver and the method must be exported.
+func (s *Shape) IsExported() bool {
+	if s.Name == "" || s.IsAnonymous() {
+		return false
+	}
+	name, _, _ := strings.Cut(s.Name, "[") // the type arguments of generics may include the package path (e.g. "List[net/http.Header]")
+	for _, name := range strings.Split(name, ".") {
+		if !token.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAnonymous reports whether the shape is anonymous function (e.g. "F.func1").
+func (s *Shape) IsAnonymous() bool {
+	return s.symbol().IsClosure()
+}
+
+// Position returns the position where the shape is defined. If it is not found, returns zero value.
+func (s *Shape) Position() token.Position {
+	if s.pos.IsValid() {
+		return s.e.position(s.pos)
+	}
+	if s.ID.pc != 0 {
+		return s.Func().Position()
+	}
+	if s.Name == "" || s.Package.Path == "" || s.Type.Name() == "" {
+		return token.Position{} // builtin or unnamed type
+	}
+	return s.Named().Position()
+}
+
+// isMethodExpression reports whether the shape is method expression (e.g. (*T).M), not method value (e.g. new(T).M).
+func (s *Shape) isMethodExpression() bool {
+	if !s.IsMethod || s.ID.pc == 0 {
+		return false
+	}
+	return !s.symbol().IsMethodValue
+}
+
+// symbol returns the parsed symbol name of the function. If the shape is not function, returns zero value.
+func (s *Shape) symbol() symbol.Symbol {
+	if s.ID.pc == 0 {
+		return symbol.Symbol{}
+	}
+	return symbol.Parse(runtime.FuncForPC(s.ID.pc).Name())
+}
+
 func (s *Shape) Struct() *Struct {
 	if s.Kind != reflect.Struct {
 		panic(fmt.Sprintf("shape %v is not Struct kind, %s", s, s.Kind))
@@ -77,14 +130,12 @@ func (s *Shape) Interface() *Interface {
 	return &Interface{Shape: s, metadata: metadata}
 }
 
-var anonymousFuncNameRegex = regexp.MustCompile(`func\d+$`)
-
 func (s *Shape) Func() *Func {
 	if s.Kind != reflect.Func && s.ID.pc == 0 {
 		panic(fmt.Sprintf("shape %v is not func kind, %s", s, s.Kind))
 	}
 	lookup := s.e.Lookup
-	if lookup == nil || s.Name == "" || (s.Package.Path == "" && anonymousFuncNameRegex.MatchString(s.Name)) {
+	if lookup == nil || s.Name == "" || s.symbol().IsClosure() { // anonymous function is not supported
 		return &Func{Shape: s}
 	}
 
@@ -121,14 +172,47 @@ func (t *Named) Name() string {
 }
 
 func (t *Named) Pos() token.Pos {
-	return t.metadata.Raw.Pos
+	if t.metadata == nil {
+		return token.NoPos
+	}
+	return t.metadata.Pos()
+}
+
+func (t *Named) Position() token.Position {
+	return t.Shape.e.position(t.Pos())
 }
 
 func (t *Named) Doc() string {
 	if t.metadata == nil {
 		return ""
 	}
-	return t.metadata.Doc()
+	return t.Shape.e.stripAnnotations(t.metadata.Doc())
+}
+
+func (t *Named) LeadingDoc() string {
+	if t.metadata == nil {
+		return ""
+	}
+	return t.Shape.e.stripAnnotations(t.metadata.LeadingDoc())
+}
+
+func (t *Named) LineComment() string {
+	if t.metadata == nil {
+		return ""
+	}
+	return t.Shape.e.stripAnnotations(t.metadata.LineComment())
+}
+
+func (t *Named) Annotations() AnnotationList {
+	return t.Shape.e.annotations(t.Pos())
+}
+
+func (t *Named) Deprecated() (string, bool) {
+	return deprecation(t.Doc())
+}
+
+func (t *Named) DocComment() *Doc {
+	return t.Shape.parseDoc(t.Doc())
 }
 
 func (t *Named) String() string {
@@ -150,23 +234,58 @@ func (s *Struct) Name() string {
 }
 
 func (s *Struct) Pos() token.Pos {
-	return s.metadata.Raw.Pos
+	if s.metadata == nil {
+		return token.NoPos
+	}
+	return s.metadata.Pos()
+}
+
+func (s *Struct) Position() token.Position {
+	return s.Shape.e.position(s.Pos())
 }
 
 func (s *Struct) Doc() string {
 	if s.metadata == nil {
 		return ""
 	}
-	return s.metadata.Doc()
+	return s.Shape.e.stripAnnotations(s.metadata.Doc())
+}
+
+func (s *Struct) LeadingDoc() string {
+	if s.metadata == nil {
+		return ""
+	}
+	return s.Shape.e.stripAnnotations(s.metadata.LeadingDoc())
+}
+
+func (s *Struct) LineComment() string {
+	if s.metadata == nil {
+		return ""
+	}
+	return s.Shape.e.stripAnnotations(s.metadata.LineComment())
+}
+
+func (s *Struct) Annotations() AnnotationList {
+	return s.Shape.e.annotations(s.Pos())
+}
+
+func (s *Struct) Deprecated() (string, bool) {
+	return deprecation(s.Doc())
+}
+
+func (s *Struct) DocComment() *Doc {
+	return s.Shape.parseDoc(s.Doc())
 }
 
 func (s *Struct) Fields() FieldList {
 	typ := s.Shape.Type
-	var comments map[string]string
+	var comments, leadingDocs, lineComments map[string]string // nil map is ok for lookup
+	var positions map[string]token.Pos
 	if s.metadata != nil {
 		comments = s.metadata.FieldComments()
-	} else {
-		comments = map[string]string{}
+		leadingDocs = s.metadata.FieldLeadingDocs()
+		lineComments = s.metadata.FieldLineComments()
+		positions = s.metadata.FieldPositions()
 	}
 
 	r := make([]*Field, typ.NumField())
@@ -175,7 +294,17 @@ func (s *Struct) Fields() FieldList {
 		rt := f.Type
 		rv := rzero(f.Type)
 		shape := s.Shape.e.extract(rt, rv)
-		r[i] = &Field{StructField: f, Shape: shape, Doc: comments[f.Name]}
+		pos := positions[f.Name]
+		r[i] = &Field{
+			StructField: f,
+			Shape:       shape,
+			Doc:         s.Shape.e.stripAnnotations(comments[f.Name]),
+			LeadingDoc:  s.Shape.e.stripAnnotations(leadingDocs[f.Name]),
+			LineComment: s.Shape.e.stripAnnotations(lineComments[f.Name]),
+			Pos:         pos,
+			Annotations: s.Shape.e.annotations(pos),
+			owner:       s.Shape,
+		}
 	}
 	return FieldList(r)
 }
@@ -212,7 +341,26 @@ func (fl FieldList) String() string {
 type Field struct {
 	reflect.StructField
 	Shape *Shape
-	Doc   string
+	Doc   string // LeadingDoc or LineComment
+	Pos   token.Pos
+
+	LeadingDoc  string
+	LineComment string
+	Annotations AnnotationList
+
+	owner *Shape // the struct shape that the field belongs to
+}
+
+func (f *Field) Position() token.Position {
+	return f.Shape.e.position(f.Pos)
+}
+
+func (f *Field) DocComment() *Doc {
+	return f.owner.parseDoc(f.Doc)
+}
+
+func (f *Field) Deprecated() (string, bool) {
+	return deprecation(f.Doc)
 }
 
 func (f *Field) String() string {
@@ -234,32 +382,141 @@ func (iface *Interface) Name() string {
 }
 
 func (iface *Interface) Pos() token.Pos {
-	return iface.metadata.Raw.Pos
+	if iface.metadata == nil {
+		return token.NoPos
+	}
+	return iface.metadata.Pos()
+}
+
+func (iface *Interface) Position() token.Position {
+	return iface.Shape.e.position(iface.Pos())
 }
 
 func (iface *Interface) Doc() string {
 	if iface.metadata == nil {
 		return ""
 	}
-	return iface.metadata.Doc()
+	return iface.Shape.e.stripAnnotations(iface.metadata.Doc())
 }
 
-func (iface *Interface) Methods() VarList {
-	typ := iface.Shape.Type
-	var comments map[string]string
-	if iface.metadata != nil {
-		comments = iface.metadata.FieldComments()
-	} else {
-		comments = map[string]string{}
+func (iface *Interface) LeadingDoc() string {
+	if iface.metadata == nil {
+		return ""
 	}
+	return iface.Shape.e.stripAnnotations(iface.metadata.LeadingDoc())
+}
 
+func (iface *Interface) LineComment() string {
+	if iface.metadata == nil {
+		return ""
+	}
+	return iface.Shape.e.stripAnnotations(iface.metadata.LineComment())
+}
+
+func (iface *Interface) Annotations() AnnotationList {
+	return iface.Shape.e.annotations(iface.Pos())
+}
+
+func (iface *Interface) Deprecated() (string, bool) {
+	return deprecation(iface.Doc())
+}
+
+func (iface *Interface) DocComment() *Doc {
+	return iface.Shape.parseDoc(iface.Doc())
+}
+
+func (iface *Interface) Methods() VarList {
+	typ := iface.Shape.Type
 	r := make([]*Var, typ.NumMethod())
 	for i := 0; i < typ.NumMethod(); i++ {
 		f := typ.Method(i)
 		rt := f.Type
 		rv := rzero(f.Type)
 		shape := iface.Shape.e.extract(rt, rv)
-		r[i] = &Var{Name: f.Name, Shape: shape, Doc: comments[f.Name]}
+
+		var m metadata.Var
+		if owner := iface.methodOwner(f.Name); owner != nil {
+			m, _ = owner.Field(f.Name) // docs are inherited from the declaring interface
+		}
+		r[i] = &Var{
+			Name:        f.Name,
+			Shape:       shape,
+			Doc:         iface.Shape.e.stripAnnotations(m.Doc),
+			LeadingDoc:  iface.Shape.e.stripAnnotations(m.LeadingDoc),
+			LineComment: iface.Shape.e.stripAnnotations(m.LineComment),
+			Pos:         m.Pos,
+			Annotations: iface.Shape.e.annotations(m.Pos),
+			owner:       iface.Shape,
+		}
+	}
+	return r
+}
+
+// Embedded returns the interfaces embedded in this interface (only direct ones).
+func (iface *Interface) Embedded() []*InterfaceRef {
+	if iface.metadata == nil {
+		return nil
+	}
+	embedded, err := iface.Shape.e.Lookup.LookupEmbeddedInterfaces(iface.metadata)
+	if err != nil {
+		log.Printf("Embedded(): %+v", err)
+		return nil
+	}
+	r := make([]*InterfaceRef, len(embedded))
+	for i, t := range embedded {
+		r[i] = iface.Shape.e.interfaceRef(t)
+	}
+	return r
+}
+
+// DeclaredIn returns the interface that declares the method. If the method is declared in this interface, returns itself.
+func (iface *Interface) DeclaredIn(method string) *InterfaceRef {
+	owner := iface.methodOwner(method)
+	if owner == nil {
+		return nil
+	}
+	if owner == iface.metadata {
+		return &InterfaceRef{Name: iface.Name(), PkgPath: iface.Shape.Package.Path, Shape: iface.Shape, Doc: iface.Doc(), Pos: iface.Pos()}
+	}
+	return iface.Shape.e.interfaceRef(owner)
+}
+
+func (iface *Interface) methodOwner(method string) *metadata.Type {
+	if iface.metadata == nil {
+		return nil
+	}
+	owner, err := iface.Shape.e.Lookup.LookupMethodOwner(iface.metadata, method)
+	if err != nil {
+		return nil
+	}
+	return owner
+}
+
+// MethodFuncs returns the methods as func views.
+// The names and comments of the args and returns are taken from the interface declaration.
+func (iface *Interface) MethodFuncs() []*Func {
+	typ := iface.Shape.Type
+	r := make([]*Func, typ.NumMethod())
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		rt := m.Type
+		rv := rzero(m.Type)
+		shape := *iface.Shape.e.extract(rt, rv) // copied
+		shape.Name = iface.Shape.Name + "." + m.Name
+		shape.IsMethod = true
+		shape.Package = iface.Shape.Package
+		r[i] = &Func{Shape: &shape, recv: iface.Shape}
+
+		if iface.metadata == nil {
+			continue
+		}
+		metadata, err := iface.Shape.e.Lookup.LookupFromInterfaceMethod(iface.metadata, m.Name)
+		if err != nil {
+			log.Printf("MethodFuncs(): %+v", err)
+			continue
+		}
+		r[i].metadata = metadata
+		r[i].Shape.pos = metadata.Pos()
 	}
 	return r
 }
@@ -279,9 +536,28 @@ func (iface *Interface) String() string {
 	return fmt.Sprintf("&Interface{Name: %q, Methods: %v, Doc: %q}", iface.Name(), methodNames, doc)
 }
 
+// InterfaceRef is the reference to the interface, found in the source code.
+type InterfaceRef struct {
+	Name    string
+	PkgPath string
+	Shape   *Shape // nil, if the interface is not extracted yet
+	Doc     string
+	Pos     token.Pos
+}
+
+func (e *Extractor) interfaceRef(t *metadata.Type) *InterfaceRef {
+	ref := &InterfaceRef{Name: t.Name(), PkgPath: t.PkgPath, Doc: e.stripAnnotations(t.Doc()), Pos: t.Pos()}
+	if pkg, ok := e.packages[t.PkgPath]; ok {
+		ref.Shape = pkg.scope.shapes[t.Name()]
+	}
+	return ref
+}
+
 type Func struct {
 	Shape    *Shape
 	metadata *metadata.Func
+
+	recv *Shape // the receiver, if it is known in advance (e.g. interface's method)
 }
 
 func (f *Func) Name() string {
@@ -289,7 +565,26 @@ func (f *Func) Name() string {
 }
 
 func (f *Func) Pos() token.Pos {
-	return f.metadata.Raw.Pos
+	if f.metadata == nil {
+		return token.NoPos
+	}
+	return f.metadata.Pos()
+}
+
+// Position returns the position of the declaration. If the source code is not available, it falls back to the runtime information.
+func (f *Func) Position() token.Position {
+	if pos := f.Pos(); pos.IsValid() {
+		return f.Shape.e.position(pos)
+	}
+	if f.Shape.ID.pc == 0 {
+		return token.Position{}
+	}
+	rfunc := runtime.FuncForPC(f.Shape.ID.pc)
+	if rfunc == nil {
+		return token.Position{}
+	}
+	filename, line := rfunc.FileLine(rfunc.Entry())
+	return token.Position{Filename: filename, Line: line}
 }
 
 func (f *Func) IsMethod() bool {
@@ -301,17 +596,23 @@ func (f *Func) IsVariadic() bool {
 
 func (f *Func) Args() VarList {
 	typ := f.Shape.Type
+	offset := 0
+	if f.Shape.isMethodExpression() {
+		offset = 1 // the first argument is the receiver
+	}
+	n := typ.NumIn() - offset
+
 	var args []metadata.Var
 	if f.metadata != nil {
 		args = f.metadata.Args()
 	} else {
-		args = make([]metadata.Var, typ.NumIn())
+		args = make([]metadata.Var, n)
 	}
 
-	r := make([]*Var, typ.NumIn())
+	r := make([]*Var, n)
 	needFillNames := f.Shape.e.Config.FillArgNames
-	for i := 0; i < typ.NumIn(); i++ {
-		rt := typ.In(i)
+	for i := 0; i < n; i++ {
+		rt := typ.In(i + offset)
 		rv := rzero(rt)
 		shape := f.Shape.e.extract(rt, rv)
 		p := args[i]
@@ -324,7 +625,16 @@ func (f *Func) Args() VarList {
 				name = fmt.Sprintf("arg%d", i)
 			}
 		}
-		r[i] = &Var{Name: name, Shape: shape, Doc: p.Doc}
+		r[i] = &Var{
+			Name:        name,
+			Shape:       shape,
+			Doc:         f.Shape.e.stripAnnotations(p.Doc),
+			LeadingDoc:  f.Shape.e.stripAnnotations(p.LeadingDoc),
+			LineComment: f.Shape.e.stripAnnotations(p.LineComment),
+			Pos:         p.Pos,
+			Annotations: f.Shape.e.annotations(p.Pos),
+			owner:       f.Shape,
+		}
 	}
 	return VarList(r)
 }
@@ -358,7 +668,16 @@ func (f *Func) Returns() VarList {
 				name = fmt.Sprintf("ret%d", i)
 			}
 		}
-		r[i] = &Var{Name: name, Shape: shape, Doc: p.Doc}
+		r[i] = &Var{
+			Name:        name,
+			Shape:       shape,
+			Doc:         f.Shape.e.stripAnnotations(p.Doc),
+			LeadingDoc:  f.Shape.e.stripAnnotations(p.LeadingDoc),
+			LineComment: f.Shape.e.stripAnnotations(p.LineComment),
+			Pos:         p.Pos,
+			Annotations: f.Shape.e.annotations(p.Pos),
+			owner:       f.Shape,
+		}
 	}
 	return VarList(r)
 }
@@ -367,9 +686,26 @@ func (f *Func) Doc() string {
 	if f.metadata == nil {
 		return ""
 	}
-	return f.metadata.Doc()
+	return f.Shape.e.stripAnnotations(f.metadata.Doc())
+}
+
+func (f *Func) Annotations() AnnotationList {
+	return f.Shape.e.annotations(f.Pos())
+}
+
+func (f *Func) Deprecated() (string, bool) {
+	return deprecation(f.Doc())
+}
+
+func (f *Func) DocComment() *Doc {
+	return f.Shape.parseDoc(f.Doc())
 }
+
+// Recv returns the receiver type name. For the methods of interface, it is the interface that has the method set, even if the method is declared in the embedded interface (see Interface.DeclaredIn()).
 func (f *Func) Recv() string {
+	if f.recv != nil {
+		return f.recv.Name
+	}
 	if f.metadata == nil {
 		if f.Shape.IsMethod {
 			return "i"
@@ -379,6 +715,31 @@ func (f *Func) Recv() string {
 	return f.metadata.Recv
 }
 
+// Receiver returns the receiver of the method, the Lv of its shape is 1 if the receiver is pointer (e.g. func (s *S) M()).
+// For method values, the receiver type cannot be taken from the func type, so the shape is found from the extracted shapes by the receiver type name.
+// If the shape is not found, the receiver has only the name and the position from the source code (Shape is nil).
+// If the func is not method, or neither the shape nor the source code is found, returns nil.
+func (f *Func) Receiver() *Var {
+	if !f.Shape.IsMethod {
+		return nil
+	}
+	shape := f.recv
+	if shape == nil {
+		shape = f.Shape.e.receiverOf(f.Shape)
+		if shape == nil && f.metadata == nil {
+			return nil
+		}
+	}
+
+	v := &Var{Shape: shape, owner: f.Shape}
+	if f.metadata != nil {
+		m := f.metadata.Receiver()
+		v.Name = m.Name
+		v.Pos = m.Pos
+	}
+	return v
+}
+
 func (f *Func) String() string {
 	doc := f.Doc()
 	tsize := f.Shape.e.Config.DocTruncationSize
@@ -416,17 +777,40 @@ func (vl VarList) String() string {
 
 type Var struct {
 	Name  string
-	Shape *Shape
-	Doc   string
+	Shape *Shape // nil, if the type is unknown (only for the receiver of method value, see Func.Receiver())
+	Doc   string // LeadingDoc or LineComment
+	Pos   token.Pos
+
+	LeadingDoc  string
+	LineComment string
+	Annotations AnnotationList
+
+	owner *Shape // the func or interface shape that the var belongs to
+}
+
+func (v *Var) Position() token.Position {
+	return v.owner.e.position(v.Pos)
+}
+
+func (v *Var) DocComment() *Doc {
+	return v.owner.parseDoc(v.Doc)
+}
+
+func (v *Var) Deprecated() (string, bool) {
+	return deprecation(v.Doc)
 }
 
 func (v *Var) String() string {
 	doc := v.Doc
-	tsize := v.Shape.e.Config.DocTruncationSize
+	tsize := v.owner.e.Config.DocTruncationSize
 	if len(doc) > tsize {
 		doc = doc[:tsize] + "..."
 	}
-	return fmt.Sprintf("&Var{Name: %q, type: %v, Doc: %q}", v.Name, v.Shape.Type, doc)
+	var typ reflect.Type
+	if v.Shape != nil { // e.g. the receiver of method value
+		typ = v.Shape.Type
+	}
+	return fmt.Sprintf("&Var{Name: %q, type: %v, Doc: %q}", v.Name, typ, doc)
 }
 
 func rzero(rt reflect.Type) reflect.Value {
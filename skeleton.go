@@ -0,0 +1,593 @@
+package reflectshape
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type SkeletonOptions struct {
+	Tag      string          // the tag name of keys, default is "yaml" for YAML, and "toml" for TOML
+	Defaults *DefaultOptions // if not nil, the zero fields are written with their default values (see SetDefaults()), ob is not modified
+}
+
+// WriteYAMLSkeleton writes the sample YAML configuration of the struct shape.
+// Every field is written with its value of ob (T or *T, if nil, the zero value or the default value), and its doc is written as the comment above it.
+// The nested structs become the nested mappings, and the empty slices and maps get an example entry.
+//
+// The keys are the tag values or the lowercased field names (same as gopkg.in/yaml), the embedded structs are flattened only with `yaml:",inline"`.
+func (s *Shape) WriteYAMLSkeleton(w io.Writer, ob interface{}, options SkeletonOptions) error {
+	defer s.e.query()()
+
+	rv, err := s.exampleValue(ob)
+	if err != nil {
+		return err
+	}
+	if options.Defaults != nil {
+		if rv, err = s.withDefaults(rv, *options.Defaults); err != nil {
+			return err
+		}
+	}
+	if options.Tag == "" {
+		options.Tag = "yaml"
+	}
+	sw := &skeletonWriter{e: s.e, tag: options.Tag, defaultKey: strings.ToLower, path: map[reflect.Type]bool{s.Type: true}}
+
+	var b strings.Builder
+	st := s.Struct()
+	if doc := st.Doc(); strings.TrimSpace(doc) != "" {
+		writeComment(&b, "", doc)
+		b.WriteString("\n")
+	}
+	sw.yamlStruct(&b, st, rv, "")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTOMLSkeleton writes the sample TOML configuration of the struct shape.
+// Every field is written with its value of ob (T or *T, if nil, the zero value or the default value), and its doc is written as the comment above it.
+// The nested structs become the sections ([a.b]), the slices of structs become the arrays of tables ([[a.b]]), and the empty slices and maps get an example entry.
+// The other nested values (e.g. the slices of slices, the slices of maps) are written as the inline arrays and the inline tables.
+//
+// The keys are the tag values or the field names (same as github.com/BurntSushi/toml), the embedded structs without tag are flattened.
+func (s *Shape) WriteTOMLSkeleton(w io.Writer, ob interface{}, options SkeletonOptions) error {
+	defer s.e.query()()
+
+	rv, err := s.exampleValue(ob)
+	if err != nil {
+		return err
+	}
+	if options.Defaults != nil {
+		if rv, err = s.withDefaults(rv, *options.Defaults); err != nil {
+			return err
+		}
+	}
+	if options.Tag == "" {
+		options.Tag = "toml"
+	}
+	sw := &skeletonWriter{e: s.e, tag: options.Tag, defaultKey: func(name string) string { return name }, inlineEmbedded: true, toml: true, path: map[reflect.Type]bool{s.Type: true}}
+
+	var b strings.Builder
+	st := s.Struct()
+	if doc := st.Doc(); strings.TrimSpace(doc) != "" {
+		writeComment(&b, "", doc)
+		b.WriteString("\n")
+	}
+	sw.tomlTable(&b, st, rv, nil)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// withDefaults returns the copy of rv with the default values set to the zero fields.
+// The nested structs referenced by the pointers are also copied, so that the values of ob are not modified.
+func (s *Shape) withDefaults(rv reflect.Value, options DefaultOptions) (reflect.Value, error) {
+	ptr := reflect.New(s.Type)
+	ptr.Elem().Set(rv)
+	copyNestedStructs(ptr.Elem(), map[reflect.Type]bool{s.Type: true})
+	if err := s.SetDefaults(ptr, options); err != nil {
+		return rv, err
+	}
+	return ptr.Elem(), nil
+}
+
+// copyNestedStructs replaces the pointers to the nested structs with the pointers to their copies (the same fields as setDefaults() follows).
+func copyNestedStructs(rv reflect.Value, path map[reflect.Type]bool) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		if !rt.Field(i).IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		ft := fv.Type()
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || path[ft] {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			copied := reflect.New(ft)
+			copied.Elem().Set(fv.Elem())
+			fv.Set(copied)
+			fv = copied.Elem()
+		}
+		path[ft] = true
+		copyNestedStructs(fv, path)
+		delete(path, ft)
+	}
+}
+
+type skeletonWriter struct {
+	e              *Extractor
+	tag            string
+	defaultKey     func(string) string
+	inlineEmbedded bool                  // if true, the embedded structs without tag are flattened
+	toml           bool                  // if true, the literals are written for TOML (e.g. nan, inf)
+	path           map[reflect.Type]bool // for recursive types
+}
+
+// skeletonField is the field written as the key, the fields of the flattened embedded structs are also included.
+type skeletonField struct {
+	*Field
+	index []int
+	key   string
+}
+
+func (sw *skeletonWriter) fields(st *Struct, index []int) []skeletonField {
+	var r []skeletonField
+	for i, f := range st.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		v, hasTag := f.Tag.Lookup(sw.tag)
+		name, opts, _ := strings.Cut(v, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+
+		inline := strings.Contains(","+opts+",", ",inline,") || (sw.inlineEmbedded && f.Anonymous && (!hasTag || name == ""))
+		if inline && f.Shape.Kind == reflect.Struct && skeletonKindOf(f.Shape.Type) == skeletonStruct && !sw.path[f.Shape.Type] {
+			r = append(r, sw.fields(f.Shape.Struct(), idx)...)
+			continue
+		}
+		if name == "" {
+			name = sw.defaultKey(f.Name)
+		}
+		r = append(r, skeletonField{Field: f, index: idx, key: name})
+	}
+	return r
+}
+
+func (sw *skeletonWriter) structOf(rt reflect.Type) *Struct {
+	return sw.e.extract(rt, rzero(rt)).Struct()
+}
+
+// YAML
+
+func (sw *skeletonWriter) yamlStruct(b *strings.Builder, st *Struct, rv reflect.Value, indent string) {
+	for _, f := range sw.fields(st, nil) {
+		fv := fieldByIndex(rv, f.index)
+		if skeletonKindOf(fv.Type()) == skeletonUnsupported {
+			continue
+		}
+		writeComment(b, indent, f.Doc)
+		sw.yamlEntry(b, indent, yamlKey(f.key), fv)
+	}
+}
+
+func (sw *skeletonWriter) yamlEntry(b *strings.Builder, indent string, key string, rv reflect.Value) {
+	rt := rv.Type()
+	switch skeletonKindOf(rt) {
+	case skeletonScalar:
+		fmt.Fprintf(b, "%s%s: %s\n", indent, key, sw.scalarLiteral(rv))
+	case skeletonStruct:
+		if sw.path[rt] {
+			fmt.Fprintf(b, "%s%s: null\n", indent, key) // recursive
+			return
+		}
+		var nested strings.Builder
+		sw.path[rt] = true
+		sw.yamlStruct(&nested, sw.structOf(rt), rv, indent+"  ")
+		delete(sw.path, rt)
+		if nested.Len() == 0 {
+			fmt.Fprintf(b, "%s%s: {}\n", indent, key)
+			return
+		}
+		fmt.Fprintf(b, "%s%s:\n%s", indent, key, nested.String())
+	case skeletonList:
+		items := listItems(rv)
+		if len(items) == 0 {
+			fmt.Fprintf(b, "%s%s: []\n", indent, key)
+			return
+		}
+		fmt.Fprintf(b, "%s%s:\n", indent, key)
+		for _, item := range items {
+			sw.yamlItem(b, indent+"  ", item)
+		}
+	case skeletonMap:
+		fmt.Fprintf(b, "%s%s:\n", indent, key)
+		for _, kv := range mapEntries(rv) {
+			sw.yamlEntry(b, indent+"  ", yamlKey(formatValue(kv[0])), kv[1])
+		}
+	}
+}
+
+func (sw *skeletonWriter) yamlItem(b *strings.Builder, indent string, rv reflect.Value) {
+	rt := rv.Type()
+	switch skeletonKindOf(rt) {
+	case skeletonScalar:
+		fmt.Fprintf(b, "%s- %s\n", indent, sw.scalarLiteral(rv))
+	case skeletonStruct, skeletonMap:
+		// the first line of the nested mapping is prefixed with "- "
+		var nested strings.Builder
+		if skeletonKindOf(rt) == skeletonMap {
+			for _, kv := range mapEntries(rv) {
+				sw.yamlEntry(&nested, indent+"  ", yamlKey(formatValue(kv[0])), kv[1])
+			}
+		} else if !sw.path[rt] {
+			sw.path[rt] = true
+			sw.yamlStruct(&nested, sw.structOf(rt), rv, indent+"  ")
+			delete(sw.path, rt)
+		}
+		if nested.Len() == 0 {
+			fmt.Fprintf(b, "%s- {}\n", indent)
+			return
+		}
+		b.WriteString(indent + "- " + strings.TrimPrefix(nested.String(), indent+"  "))
+	case skeletonList:
+		fmt.Fprintf(b, "%s-\n", indent)
+		for _, item := range listItems(rv) {
+			sw.yamlItem(b, indent+"  ", item)
+		}
+	}
+}
+
+func yamlKey(key string) string {
+	if isBareKey(key) && !isYAMLNonString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// isYAMLNonString reports whether the plain scalar is not read as a string in YAML (e.g. true, on, null, 1, 2006-01-02).
+func isYAMLNonString(key string) bool {
+	switch strings.ToLower(key) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return true
+	}
+	key = strings.TrimPrefix(key, "-")
+	return key == "" || ('0' <= key[0] && key[0] <= '9')
+}
+
+// TOML
+
+func (sw *skeletonWriter) tomlTable(b *strings.Builder, st *Struct, rv reflect.Value, table []string) {
+	fields := sw.fields(st, nil)
+
+	// the keys of the table must be written before the sub-tables
+	for _, f := range fields {
+		fv := fieldByIndex(rv, f.index)
+		switch skeletonKindOf(fv.Type()) {
+		case skeletonScalar:
+			writeComment(b, "", f.Doc)
+			fmt.Fprintf(b, "%s = %s\n", tomlKey(f.key), sw.scalarLiteral(fv))
+		case skeletonList:
+			if skeletonKindOf(fv.Type().Elem()) == skeletonStruct {
+				continue // array of tables
+			}
+			writeComment(b, "", f.Doc)
+			fmt.Fprintf(b, "%s = %s\n", tomlKey(f.key), sw.tomlInline(fv))
+		}
+	}
+
+	for _, f := range fields {
+		fv := fieldByIndex(rv, f.index)
+		rt := fv.Type()
+		name := append(append([]string{}, table...), f.key)
+		switch skeletonKindOf(rt) {
+		case skeletonStruct:
+			if sw.path[rt] {
+				continue // recursive
+			}
+			b.WriteString("\n")
+			writeComment(b, "", f.Doc)
+			fmt.Fprintf(b, "[%s]\n", tomlTableName(name))
+			sw.path[rt] = true
+			sw.tomlTable(b, sw.structOf(rt), fv, name)
+			delete(sw.path, rt)
+		case skeletonList:
+			elem := rt.Elem()
+			for elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if skeletonKindOf(elem) != skeletonStruct || sw.path[elem] {
+				continue
+			}
+			b.WriteString("\n")
+			writeComment(b, "", f.Doc)
+			for i, item := range listItems(fv) {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				fmt.Fprintf(b, "[[%s]]\n", tomlTableName(name))
+				sw.path[elem] = true
+				sw.tomlTable(b, sw.structOf(elem), item, name)
+				delete(sw.path, elem)
+			}
+		case skeletonMap:
+			b.WriteString("\n")
+			writeComment(b, "", f.Doc)
+			fmt.Fprintf(b, "[%s]\n", tomlTableName(name))
+			entries := mapEntries(fv)
+			for _, kv := range entries {
+				if skeletonKindOf(kv[1].Type()) != skeletonStruct {
+					fmt.Fprintf(b, "%s = %s\n", tomlKey(formatValue(kv[0])), sw.tomlInline(kv[1]))
+				}
+			}
+			for _, kv := range entries {
+				if elem := kv[1].Type(); skeletonKindOf(elem) == skeletonStruct && !sw.path[elem] {
+					sub := append(append([]string{}, name...), formatValue(kv[0]))
+					fmt.Fprintf(b, "\n[%s]\n", tomlTableName(sub))
+					sw.path[elem] = true
+					sw.tomlTable(b, sw.structOf(elem), kv[1], sub)
+					delete(sw.path, elem)
+				}
+			}
+		}
+	}
+}
+
+// tomlInline returns the inline value (e.g. [[1, 2], [3]], [{ a = 1 }]), for the values that cannot be written as the tables.
+func (sw *skeletonWriter) tomlInline(rv reflect.Value) string {
+	rv = deref(rv)
+	rt := rv.Type()
+	switch skeletonKindOf(rt) {
+	case skeletonScalar:
+		return sw.scalarLiteral(rv)
+	case skeletonList:
+		items := listItems(rv)
+		literals := make([]string, len(items))
+		for i, item := range items {
+			literals[i] = sw.tomlInline(item)
+		}
+		return "[" + strings.Join(literals, ", ") + "]"
+	case skeletonMap:
+		entries := mapEntries(rv)
+		pairs := make([]string, len(entries))
+		for i, kv := range entries {
+			pairs[i] = tomlKey(formatValue(kv[0])) + " = " + sw.tomlInline(kv[1])
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	case skeletonStruct:
+		if sw.path[rt] {
+			return "{}" // recursive
+		}
+		sw.path[rt] = true
+		defer delete(sw.path, rt)
+		var pairs []string
+		for _, f := range sw.fields(sw.structOf(rt), nil) {
+			fv := fieldByIndex(rv, f.index)
+			if skeletonKindOf(fv.Type()) == skeletonUnsupported {
+				continue
+			}
+			pairs = append(pairs, tomlKey(f.key)+" = "+sw.tomlInline(fv))
+		}
+		if len(pairs) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	default:
+		return `""` // unreachable, the unsupported values are skipped
+	}
+}
+
+func tomlKey(key string) string {
+	if isBareKey(key) {
+		return key
+	}
+	return tomlQuote(key)
+}
+
+// tomlQuote returns the basic string of TOML, the escapes of strconv.Quote() (e.g. \x01, \a) are not valid in TOML.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func tomlTableName(keys []string) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = tomlKey(k)
+	}
+	return strings.Join(parts, ".")
+}
+
+// helpers
+
+type skeletonKind int
+
+const (
+	skeletonUnsupported skeletonKind = iota
+	skeletonScalar
+	skeletonStruct
+	skeletonList
+	skeletonMap
+)
+
+func skeletonKindOf(rt reflect.Type) skeletonKind {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == rdurationType || rt.Implements(rtextMarshalerType) {
+		return skeletonScalar
+	}
+	switch rt.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return skeletonScalar
+	case reflect.Struct:
+		return skeletonStruct
+	case reflect.Slice, reflect.Array:
+		if skeletonKindOf(rt.Elem()) == skeletonUnsupported {
+			return skeletonUnsupported
+		}
+		return skeletonList
+	case reflect.Map:
+		if skeletonKindOf(rt.Key()) != skeletonScalar || skeletonKindOf(rt.Elem()) == skeletonUnsupported {
+			return skeletonUnsupported
+		}
+		return skeletonMap
+	default:
+		return skeletonUnsupported
+	}
+}
+
+// scalarLiteral returns the literal of the scalar value for YAML or TOML.
+func (sw *skeletonWriter) scalarLiteral(rv reflect.Value) string {
+	rv = deref(rv)
+	rt := rv.Type()
+	if rt == rdurationType || rt.Implements(rtextMarshalerType) {
+		return sw.quote(formatValue(rv))
+	}
+	switch rt.Kind() {
+	case reflect.String:
+		return sw.quote(rv.String())
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			return sw.special("nan")
+		case math.IsInf(f, 1):
+			return sw.special("inf")
+		case math.IsInf(f, -1):
+			return "-" + sw.special("inf")
+		}
+		s := strconv.FormatFloat(f, 'g', -1, rt.Bits())
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0" // not integer
+		}
+		return s
+	default:
+		return formatValue(rv)
+	}
+}
+
+// quote returns the quoted string (YAML: the double-quoted scalar, TOML: the basic string).
+func (sw *skeletonWriter) quote(s string) string {
+	if sw.toml {
+		return tomlQuote(s)
+	}
+	return strconv.Quote(s)
+}
+
+// special returns the literal of the special float value (YAML: .nan, .inf, TOML: nan, inf).
+func (sw *skeletonWriter) special(name string) string {
+	if sw.toml {
+		return name
+	}
+	return "." + name
+}
+
+// listItems returns the items of the slice or array, the empty slice gets an example item.
+func listItems(rv reflect.Value) []reflect.Value {
+	rv = deref(rv)
+	if rv.Len() == 0 && rv.Kind() == reflect.Slice {
+		return []reflect.Value{deref(reflect.New(rv.Type().Elem()).Elem())}
+	}
+	items := make([]reflect.Value, rv.Len())
+	for i := range items {
+		items[i] = deref(rv.Index(i))
+	}
+	return items
+}
+
+// mapEntries returns the key-value pairs of the map sorted by keys, the empty map gets an example entry.
+func mapEntries(rv reflect.Value) [][2]reflect.Value {
+	rv = deref(rv)
+	rt := rv.Type()
+	if rv.Len() == 0 {
+		k := reflect.New(rt.Key()).Elem()
+		if k.Kind() == reflect.String {
+			k.SetString("key")
+		}
+		return [][2]reflect.Value{{k, deref(reflect.New(rt.Elem()).Elem())}}
+	}
+	entries := make([][2]reflect.Value, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		entries = append(entries, [2]reflect.Value{iter.Key(), deref(iter.Value())})
+	}
+	sort.Slice(entries, func(i, j int) bool { return formatValue(entries[i][0]) < formatValue(entries[j][0]) })
+	return entries
+}
+
+// fieldByIndex is the version of reflect.Value.FieldByIndex that treats nil pointers as zero values.
+func fieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		rv = deref(rv).Field(i)
+	}
+	return deref(rv)
+}
+
+// deref returns the value pointed to, nil pointers are treated as zero values (except for TextMarshaler).
+func deref(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer {
+		if rv.Type().Implements(rtextMarshalerType) && !rv.IsNil() {
+			return rv
+		}
+		if rv.IsNil() {
+			rv = reflect.New(rv.Type().Elem()).Elem()
+			continue
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
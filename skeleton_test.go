@@ -0,0 +1,307 @@
+package reflectshape_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	reflectshape "github.com/podhmo/reflect-shape"
+)
+
+// ProxyConfig is the configuration of the proxy.
+type ProxyConfig struct {
+	// Listen address.
+	Listen    string        `yaml:"listen" toml:"listen"`
+	Timeout   time.Duration // request timeout
+	Ratio     float64
+	Hosts     []string
+	Upstreams []Upstream
+	Headers   map[string]string
+	DB        *DBConfig
+	Log       LogConfig `yaml:"log" toml:"log"`
+	Parent    *ProxyConfig
+}
+
+// Upstream is the backend server.
+type Upstream struct {
+	// Name of the upstream.
+	Name   string `yaml:"name" toml:"name"`
+	Weight int    `yaml:"weight" toml:"weight"`
+}
+
+func TestWriteSkeleton(t *testing.T) {
+	ob := &ProxyConfig{Listen: ":8080", Timeout: 10 * time.Second, Ratio: 1, Headers: map[string]string{"X-Forwarded-For": "client", "Via": "proxy"}}
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteYAMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `# ProxyConfig is the configuration of the proxy.
+
+# Listen address.
+listen: ":8080"
+# request timeout
+timeout: "10s"
+ratio: 1.0
+hosts:
+  - ""
+upstreams:
+  - # Name of the upstream.
+    name: ""
+    weight: 0
+headers:
+  Via: "proxy"
+  X-Forwarded-For: "client"
+db:
+  url: ""
+  maxconns: 0
+log:
+  level: ""
+parent: null
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteYAMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteTOMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `# ProxyConfig is the configuration of the proxy.
+
+# Listen address.
+listen = ":8080"
+# request timeout
+Timeout = "10s"
+Ratio = 1.0
+Hosts = [""]
+
+[[Upstreams]]
+# Name of the upstream.
+name = ""
+weight = 0
+
+[Headers]
+Via = "proxy"
+X-Forwarded-For = "client"
+
+[DB]
+URL = ""
+MaxConns = 0
+
+[log]
+Level = ""
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteTOMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+}
+
+type NestedValues struct {
+	Matrix   [][]int
+	Rules    []map[string]string
+	Groups   map[string][]string
+	Limits   map[string]map[string]int
+	Backends map[string][]Upstream
+	Max      float64
+	Min      float64
+	Missing  float64
+}
+
+func TestWriteSkeletonNestedValues(t *testing.T) {
+	ob := &NestedValues{
+		Matrix:   [][]int{{1, 2}, {3}},
+		Rules:    []map[string]string{{"path": "/"}},
+		Groups:   map[string][]string{"admin": {"foo", "bar"}},
+		Limits:   map[string]map[string]int{"api": {"rps": 10}},
+		Backends: map[string][]Upstream{"main": {{Name: "a", Weight: 1}}},
+		Max:      math.Inf(1),
+		Min:      math.Inf(-1),
+		Missing:  math.NaN(),
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteYAMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `matrix:
+  -
+    - 1
+    - 2
+  -
+    - 3
+rules:
+  - path: "/"
+groups:
+  admin:
+    - "foo"
+    - "bar"
+limits:
+  api:
+    rps: 10
+backends:
+  main:
+    - # Name of the upstream.
+      name: "a"
+      weight: 1
+max: .inf
+min: -.inf
+missing: .nan
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteYAMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteTOMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `Matrix = [[1, 2], [3]]
+Rules = [{ path = "/" }]
+Max = inf
+Min = -inf
+Missing = nan
+
+[Groups]
+admin = ["foo", "bar"]
+
+[Limits]
+api = { rps = 10 }
+
+[Backends]
+main = [{ name = "a", weight = 1 }]
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteTOMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("no-side-effect", func(t *testing.T) {
+		cfg := &reflectshape.Config{IncludeGoTestFiles: true}
+		shape := cfg.Extract(ob)
+		want := len(cfg.VisitedShapes())
+		if err := shape.WriteYAMLSkeleton(io.Discard, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if err := shape.WriteTOMLSkeleton(io.Discard, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if got := len(cfg.VisitedShapes()); want != got {
+			t.Errorf("Config.VisitedShapes(): must not be changed, %d != %d", want, got)
+		}
+	})
+}
+
+func TestWriteSkeletonDefaults(t *testing.T) {
+	options := reflectshape.SkeletonOptions{Defaults: &reflectshape.DefaultOptions{}}
+
+	t.Run("nil", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ProxyConfig{}).WriteYAMLSkeleton(&buf, nil, options); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `# ProxyConfig is the configuration of the proxy.
+
+# Listen address.
+listen: ""
+# request timeout
+timeout: "0s"
+ratio: 0.0
+hosts:
+  - ""
+upstreams:
+  - # Name of the upstream.
+    name: ""
+    weight: 0
+headers:
+  key: ""
+db:
+  url: "postgres://localhost:5432"
+  maxconns: 10
+log:
+  level: "info"
+parent: null
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteYAMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("not-modified", func(t *testing.T) {
+		ob := &ProxyConfig{DB: &DBConfig{MaxConns: 5}}
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteTOMLSkeleton(&buf, ob, options); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `
+[DB]
+URL = "postgres://localhost:5432"
+MaxConns = 5
+
+[log]
+Level = "info"
+`
+		if got := buf.String(); !strings.HasSuffix(got, want) {
+			t.Errorf("Shape.WriteTOMLSkeleton(): want suffix %q, but got %q", want, got)
+		}
+		if diff := cmp.Diff(&ProxyConfig{DB: &DBConfig{MaxConns: 5}}, ob); diff != "" {
+			t.Errorf("ob must not be modified: -want, +got: \n%v", diff)
+		}
+	})
+}
+
+type QuotedValues struct {
+	Labels map[string]string
+}
+
+func TestWriteSkeletonQuoted(t *testing.T) {
+	ob := &QuotedValues{Labels: map[string]string{"true": "on", "On": "\x01\a\v", "1": "\"\\\t", "-2": "", "a.b": "é", "key": "null"}}
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteYAMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `labels:
+  "-2": ""
+  "1": "\"\\\t"
+  "On": "\x01\a\v"
+  "a.b": "é"
+  key: "null"
+  "true": "on"
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteYAMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cfg.Extract(ob).WriteTOMLSkeleton(&buf, ob, reflectshape.SkeletonOptions{}); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		want := `
+[Labels]
+-2 = ""
+1 = "\"\\\t"
+On = "\u0001\u0007\u000B"
+"a.b" = "é"
+key = "null"
+true = "on"
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("Shape.WriteTOMLSkeleton(): -want, +got: \n%v", diff)
+		}
+	})
+}
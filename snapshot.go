@@ -0,0 +1,170 @@
+package reflectshape
+
+import (
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Snapshot is the serializable form of the API surface that the extractor sees (e.g. to be stored as JSON file).
+type Snapshot struct {
+	Shapes []*ShapeSnapshot `json:"shapes"`
+}
+
+// Lookup returns the shape snapshot by the canonical full name, if not found, returns nil.
+func (s *Snapshot) Lookup(name string) *ShapeSnapshot {
+	for _, x := range s.Shapes {
+		if x.Name == name {
+			return x
+		}
+	}
+	return nil
+}
+
+type ShapeSnapshot struct {
+	Name     string `json:"name"` // the canonical full name (e.g. "github.com/foo/bar.Person")
+	Kind     string `json:"kind"`
+	Type     string `json:"type"` // the canonical type string of the underlying type
+	Doc      string `json:"doc,omitempty"`
+	Position string `json:"position,omitempty"`
+
+	Fields   []*VarSnapshot `json:"fields,omitempty"`  // exported fields (struct)
+	Methods  []*VarSnapshot `json:"methods,omitempty"` // exported methods (struct, interface, and the other named types)
+	Args     []*VarSnapshot `json:"args,omitempty"`    // (func)
+	Returns  []*VarSnapshot `json:"returns,omitempty"` // (func)
+	Variadic bool           `json:"variadic,omitempty"`
+}
+
+type VarSnapshot struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // the canonical type string (for methods, the signature without "func")
+	Tag      string `json:"tag,omitempty"`
+	Doc      string `json:"doc,omitempty"`
+	Position string `json:"position,omitempty"`
+}
+
+// Snapshot returns the snapshot of the exported shapes reachable from the visited shapes.
+// The shapes in the packages that are not in any module (e.g. standard library) are included only if they are visited directly.
+func (e *Extractor) Snapshot() *Snapshot {
+	defer e.query()()
+
+	visited := e.VisitedShapes()
+	direct := make(map[ID]bool, len(visited))
+	for _, s := range visited {
+		direct[s.ID] = true
+	}
+
+	var shapes []*ShapeSnapshot
+	for _, s := range e.Reachable(visited...) {
+		if !s.IsExported() || s.Package == nil || s.Package.Path == "" {
+			continue
+		}
+		if s.Package.Module() == nil && !direct[s.ID] {
+			continue
+		}
+		shapes = append(shapes, e.snapshot(s))
+	}
+	sort.Slice(shapes, func(i, j int) bool { return shapes[i].Name < shapes[j].Name })
+	return &Snapshot{Shapes: shapes}
+}
+
+// Snapshot returns the serializable form of the shape.
+func (s *Shape) Snapshot() *ShapeSnapshot {
+	defer s.e.query()()
+	return s.e.snapshot(s)
+}
+
+func (e *Extractor) snapshot(s *Shape) *ShapeSnapshot {
+	w := &typeWriter{}
+	w.writeType(s.Type, true)
+	ss := &ShapeSnapshot{
+		Name: s.canonicalName(),
+		Kind: s.Kind.String(),
+		Type: w.String(),
+	}
+
+	switch {
+	case s.ID.pc != 0:
+		fn := s.Func()
+		ss.Doc = fn.Doc()
+		ss.Position = positionString(fn.Position())
+		ss.Variadic = fn.IsVariadic()
+		for _, v := range fn.Args() {
+			ss.Args = append(ss.Args, varSnapshot(v))
+		}
+		for _, v := range fn.Returns() {
+			ss.Returns = append(ss.Returns, varSnapshot(v))
+		}
+		return ss
+	case s.Kind == reflect.Struct:
+		st := s.Struct()
+		ss.Doc = st.Doc()
+		ss.Position = positionString(st.Position())
+		for _, f := range st.Fields() {
+			if !f.IsExported() {
+				continue
+			}
+			ss.Fields = append(ss.Fields, &VarSnapshot{
+				Name:     f.Name,
+				Type:     typeString(f.Shape),
+				Tag:      string(f.Tag),
+				Doc:      f.Doc,
+				Position: positionString(f.Position()),
+			})
+		}
+	case s.Kind == reflect.Interface:
+		iface := s.Interface()
+		ss.Doc = iface.Doc()
+		ss.Position = positionString(iface.Position())
+		for _, m := range iface.Methods() {
+			w := &typeWriter{}
+			w.writeSignature(m.Shape.Type, 0)
+			ss.Methods = append(ss.Methods, &VarSnapshot{
+				Name:     m.Name,
+				Type:     w.String(),
+				Doc:      m.Doc,
+				Position: positionString(m.Position()),
+			})
+		}
+		return ss
+	case s.Package != nil && s.Package.Path != "": // not builtin types
+		named := s.Named()
+		ss.Doc = named.Doc()
+		ss.Position = positionString(named.Position())
+	}
+
+	// the method set of the named type (including the methods of the pointer receiver)
+	mt := reflect.PointerTo(s.Type)
+	for i, n := 0, mt.NumMethod(); i < n; i++ {
+		m := mt.Method(i)
+		w := &typeWriter{}
+		w.writeSignature(m.Type, 1)
+		ss.Methods = append(ss.Methods, &VarSnapshot{Name: m.Name, Type: w.String()})
+	}
+	return ss
+}
+
+func varSnapshot(v *Var) *VarSnapshot {
+	return &VarSnapshot{
+		Name:     v.Name,
+		Type:     typeString(v.Shape),
+		Doc:      v.Doc,
+		Position: positionString(v.Position()),
+	}
+}
+
+// typeString returns the canonical type string of the shape, including the pointer level.
+func typeString(s *Shape) string {
+	w := &typeWriter{}
+	w.WriteString(strings.Repeat("*", s.Lv))
+	w.writeType(s.Type, false)
+	return w.String()
+}
+
+func positionString(pos token.Position) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return pos.String()
+}
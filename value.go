@@ -0,0 +1,174 @@
+package reflectshape
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	rdurationType        = reflect.TypeOf(time.Duration(0))
+	rtextUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rtextMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// setString parses the string and sets the value. rv must be settable.
+// The basic kinds, time.Duration, encoding.TextUnmarshaler, pointers of them, and slices and maps of them (e.g. "a,b", "k=v,k2=v2") are supported.
+func setString(rv reflect.Value, s string) error {
+	rt := rv.Type()
+	if reflect.PointerTo(rt).Implements(rtextUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if rt == rdurationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+
+	switch rt.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 0, rt.Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, err := strconv.ParseUint(s, 0, rt.Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, rt.Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(v)
+	case reflect.Pointer:
+		v := reflect.New(rt.Elem())
+		if err := setString(v.Elem(), s); err != nil {
+			return err
+		}
+		rv.Set(v)
+	case reflect.Slice:
+		parts := splitList(s)
+		v := reflect.MakeSlice(rt, len(parts), len(parts))
+		for i, p := range parts {
+			if err := setString(v.Index(i), p); err != nil {
+				return fmt.Errorf("[%d] %w", i, err)
+			}
+		}
+		rv.Set(v)
+	case reflect.Map:
+		parts := splitList(s)
+		v := reflect.MakeMapWithSize(rt, len(parts))
+		for _, p := range parts {
+			k, val, ok := strings.Cut(p, "=")
+			if !ok {
+				return fmt.Errorf("%q is not key=value form", p)
+			}
+			kv := reflect.New(rt.Key()).Elem()
+			if err := setString(kv, strings.TrimSpace(k)); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+			vv := reflect.New(rt.Elem()).Elem()
+			if err := setString(vv, strings.TrimSpace(val)); err != nil {
+				return fmt.Errorf("[%s] %w", k, err)
+			}
+			v.SetMapIndex(kv, vv)
+		}
+		rv.Set(v)
+	default:
+		return fmt.Errorf("unsupported kind %s", rt.Kind())
+	}
+	return nil
+}
+
+// splitList splits the comma separated values, the empty string is the empty list.
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// canSetString reports whether setString() supports the type.
+func canSetString(rt reflect.Type) bool {
+	if reflect.PointerTo(rt).Implements(rtextUnmarshalerType) || rt == rdurationType {
+		return true
+	}
+	switch rt.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer, reflect.Slice:
+		return canSetString(rt.Elem())
+	case reflect.Map:
+		return canSetString(rt.Key()) && canSetString(rt.Elem())
+	default:
+		return false
+	}
+}
+
+// formatValue returns the string form of the value, it is the inverse of setString().
+func formatValue(rv reflect.Value) string {
+	if !rv.IsValid() {
+		return ""
+	}
+	rt := rv.Type()
+	if rt.Implements(rtextMarshalerType) && (rt.Kind() != reflect.Pointer || !rv.IsNil()) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	if rt == rdurationType {
+		return time.Duration(rv.Int()).String()
+	}
+
+	switch rt.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return ""
+		}
+		return formatValue(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			parts[i] = formatValue(rv.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			parts = append(parts, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(rv.Interface())
+	}
+}